@@ -1,19 +1,84 @@
 package main
 
 import (
-	"log"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
 
 	"github.com/almostinf/order_delivery_service/config"
 	"github.com/almostinf/order_delivery_service/internal/app"
+	"github.com/almostinf/order_delivery_service/pkg/logger"
 )
 
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "Usage:\n  %s [FLAGS]                  run the service\n  %s migrate [FLAGS] COMMAND  run migrations, COMMAND is one of: %s\n",
+		os.Args[0], os.Args[0], strings.Join(app.MigrateCommands, ", "))
+	flag.PrintDefaults()
+}
+
 func main() {
-	// Configuration
-	cfg, err := config.NewConfig()
+	configPath := flag.String("config", configPathFromEnv(), "path to the config file, CONFIG_PATH by default")
+	flag.Usage = usage
+	flag.Parse()
+
+	switch flag.Arg(0) {
+	case "":
+		// Configuration
+		cfg, err := config.NewConfig(*configPath)
+		if err != nil {
+			// The log level comes from the config, so the error is logged at the default one
+			logger.New("").Fatal(fmt.Errorf("main - config.NewConfig: %w", err))
+		}
+
+		// Run
+		app.Run(cfg)
+	case "migrate":
+		migrate(*configPath, flag.Args()[1:])
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// migrate runs the migrate subcommand. The flags may also follow the
+// subcommand, and only the Postgres section of the config is required.
+func migrate(configPath string, args []string) {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	flags.StringVar(&configPath, "config", configPath, "path to the config file, CONFIG_PATH by default")
+	flags.Usage = usage
+	_ = flags.Parse(args) // exits on error
+
+	if flags.NArg() < 1 || !isMigrateCommand(flags.Arg(0)) {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.NewMigrateConfig(configPath)
 	if err != nil {
-		log.Fatalf("Config error: %s", err)
+		logger.New("").Fatal(fmt.Errorf("main - config.NewMigrateConfig: %w", err))
+	}
+
+	l := logger.New(cfg.Log.Level)
+	if err := app.Migrate(l, cfg.PG.URL, flags.Arg(0), flags.Args()[1:]...); err != nil {
+		l.Fatal(fmt.Errorf("main - app.Migrate: %w", err))
+	}
+}
+
+func isMigrateCommand(command string) bool {
+	for _, c := range app.MigrateCommands {
+		if c == command {
+			return true
+		}
+	}
+
+	return false
+}
+
+func configPathFromEnv() string {
+	if path, ok := os.LookupEnv("CONFIG_PATH"); ok && path != "" {
+		return path
 	}
 
-	// Run
-	app.Run(cfg)
+	return config.DefaultPath
 }
@@ -5,6 +5,7 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
@@ -15,10 +16,13 @@ import (
 // URL for the PostgreSQL database.
 type (
 	Config struct {
-		App  `yaml:"app"`
-		HTTP `yaml:"http"`
-		PG   `yaml:"postgres"`
-		Log  `yaml:"logger"`
+		App       `yaml:"app"`
+		HTTP      `yaml:"http"`
+		PG        `yaml:"postgres"`
+		Log       `yaml:"logger"`
+		Auth      `yaml:"auth"`
+		RateLimit `yaml:"rate_limit"`
+		Tracing   `yaml:"tracing"`
 	}
 
 	App struct {
@@ -26,35 +30,127 @@ type (
 		Version string `env-required:"true" yaml:"version" env:"APP_VERSION"`
 	}
 
+	// HTTP configures the server. ShutdownTimeout bounds the time the
+	// in-flight requests get to finish on SIGINT/SIGTERM. The client IP is
+	// only read from X-Forwarded-For when the request comes from one of the
+	// TrustedProxies (IPs or CIDRs), none by default.
 	HTTP struct {
-		Port string `env-required:"true" yaml:"port" env:"HTTP_PORT"`
+		Port            string        `env-required:"true" yaml:"port" env:"HTTP_PORT"`
+		ReadTimeout     time.Duration `yaml:"read_timeout" env:"HTTP_READ_TIMEOUT" env-default:"5s"`
+		WriteTimeout    time.Duration `yaml:"write_timeout" env:"HTTP_WRITE_TIMEOUT" env-default:"30s"`
+		IdleTimeout     time.Duration `yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT" env-default:"60s"`
+		ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"HTTP_SHUTDOWN_TIMEOUT" env-default:"30s"`
+		TrustedProxies  []string      `yaml:"trusted_proxies" env:"HTTP_TRUSTED_PROXIES"`
 	}
 
+	// PG configures the pool. ConnTimeout is the pause between the
+	// connection attempts, zero StatementTimeout disables it.
 	PG struct {
-		Dialect string `env-requered:"true" yaml:"dialect" env:"DIALECT"`
-		URL     string `env-required:"true" yaml:"pg_url" env:"PG_URL"`
-		PoolMax int    `env-required:"true" yaml:"pool_max" env:"PG_POOL_MAX"`
+		Dialect          string        `env-requered:"true" yaml:"dialect" env:"DIALECT"`
+		URL              string        `env-required:"true" yaml:"pg_url" env:"PG_URL"`
+		PoolMax          int           `env-required:"true" yaml:"pool_max" env:"PG_POOL_MAX"`
+		PoolMin          int           `yaml:"pool_min" env:"PG_POOL_MIN" env-default:"0"`
+		ConnAttempts     int           `yaml:"conn_attempts" env:"PG_CONN_ATTEMPTS" env-default:"10"`
+		ConnTimeout      time.Duration `yaml:"conn_timeout" env:"PG_CONN_TIMEOUT" env-default:"1s"`
+		StatementTimeout time.Duration `yaml:"statement_timeout" env:"PG_STATEMENT_TIMEOUT" env-default:"0s"`
+		// AutoMigrate runs the up migrations on startup, replicas starting
+		// together take turns under an advisory lock
+		AutoMigrate bool `yaml:"auto_migrate" env:"PG_AUTO_MIGRATE" env-default:"true"`
 	}
 
 	Log struct {
 		Level string `env-required:"true" yaml:"log_level" env:"LOG_LEVEL"`
 	}
+
+	// Auth configures the authentication of the API clients. APIKeys maps
+	// a key to the role of its client, JWTSecret verifies HS256 tokens.
+	// Every route is public when Enabled is false.
+	Auth struct {
+		Enabled   bool              `yaml:"enabled" env:"AUTH_ENABLED" env-default:"true"`
+		APIKeys   map[string]string `yaml:"api_keys" env:"AUTH_API_KEYS"`
+		JWTSecret string            `yaml:"jwt_secret" env:"AUTH_JWT_SECRET"`
+	}
+
+	// RateLimit configures the token bucket of every client. Routes
+	// override the default bucket for single routes. Store is "memory" or
+	// "postgres", the latter shares the buckets between replicas. Every IP
+	// also gets an IPRPS bucket checked before authentication, so requests
+	// with bad credentials are limited too.
+	RateLimit struct {
+		Enabled bool             `yaml:"enabled" env:"RATE_LIMIT_ENABLED" env-default:"true"`
+		Store   string           `yaml:"store" env:"RATE_LIMIT_STORE" env-default:"memory"`
+		RPS     float64          `yaml:"rps" env:"RATE_LIMIT_RPS" env-default:"1"`
+		Burst   int              `yaml:"burst" env:"RATE_LIMIT_BURST" env-default:"10"`
+		Idle    time.Duration    `yaml:"idle" env:"RATE_LIMIT_IDLE" env-default:"10m"`
+		IPRPS   float64          `yaml:"ip_rps" env:"RATE_LIMIT_IP_RPS" env-default:"10"`
+		IPBurst int              `yaml:"ip_burst" env:"RATE_LIMIT_IP_BURST" env-default:"50"`
+		Routes  []RouteRateLimit `yaml:"routes"`
+	}
+
+	RouteRateLimit struct {
+		Method string  `yaml:"method"`
+		Path   string  `yaml:"path"`
+		RPS    float64 `yaml:"rps"`
+		Burst  int     `yaml:"burst"`
+	}
+
+	// Tracing configures the OpenTelemetry spans. Exporter is "stdout" or
+	// "otlp", the latter sends them over OTLP/HTTP to Endpoint (host:port).
+	Tracing struct {
+		Enabled     bool    `yaml:"enabled" env:"TRACING_ENABLED" env-default:"false"`
+		Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER" env-default:"stdout"`
+		Endpoint    string  `yaml:"endpoint" env:"TRACING_ENDPOINT"`
+		Insecure    bool    `yaml:"insecure" env:"TRACING_INSECURE" env-default:"false"`
+		SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" env-default:"1"`
+	}
 )
 
+// DefaultPath is used when neither the --config flag nor the CONFIG_PATH
+// variable is set.
+const DefaultPath = "./config/config.yml"
+
 // Creates a new config entity after reading the configuration values
-// from the YAML file and environment variables.
-func NewConfig() (*Config, error) {
+// from the YAML file at the path and environment variables, and validates
+// them.
+func NewConfig(path string) (*Config, error) {
 	cfg := &Config{}
+	if err := read(path, cfg); err != nil {
+		return nil, err
+	}
 
-	err := cleanenv.ReadConfig("./config/config.yml", cfg)
-	if err != nil {
-		return nil, fmt.Errorf("config error: %w", err)
+	if err := cfg.Validate(); err != nil {
+		return nil, err
 	}
 
-	err = cleanenv.ReadEnv(cfg)
-	if err != nil {
+	return cfg, nil
+}
+
+// NewMigrateConfig reads only the Postgres section and the log level, the
+// ones the migrate subcommand uses, so the other sections can be left out.
+func NewMigrateConfig(path string) (*Config, error) {
+	var sections struct {
+		PG  `yaml:"postgres"`
+		Log struct {
+			Level string `yaml:"log_level" env:"LOG_LEVEL"`
+		} `yaml:"logger"`
+	}
+	if err := read(path, &sections); err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{PG: sections.PG, Log: Log{Level: sections.Log.Level}}
+	if err := cfg.ValidatePG(); err != nil {
 		return nil, err
 	}
 
 	return cfg, nil
 }
+
+func read(path string, cfg interface{}) error {
+	err := cleanenv.ReadConfig(path, cfg)
+	if err != nil {
+		return fmt.Errorf("config error: %w", err)
+	}
+
+	return cleanenv.ReadEnv(cfg)
+}
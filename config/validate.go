@@ -0,0 +1,139 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ValidationError lists every invalid field of the config, so all of them
+// can be fixed at once.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid config:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+type validator struct {
+	problems []string
+}
+
+func (v *validator) check(ok bool, field, format string, args ...interface{}) {
+	if !ok {
+		v.problems = append(v.problems, field+": "+fmt.Sprintf(format, args...))
+	}
+}
+
+// Validate checks the values the service can't start with.
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	port, err := strconv.Atoi(c.HTTP.Port)
+	v.check(err == nil && port > 0 && port <= 65535, "http.port", "must be a number in 1..65535, got %q", c.HTTP.Port)
+	v.check(c.HTTP.ReadTimeout > 0, "http.read_timeout", "must be positive, got %s", c.HTTP.ReadTimeout)
+	v.check(c.HTTP.WriteTimeout > 0, "http.write_timeout", "must be positive, got %s", c.HTTP.WriteTimeout)
+	v.check(c.HTTP.IdleTimeout > 0, "http.idle_timeout", "must be positive, got %s", c.HTTP.IdleTimeout)
+	v.check(c.HTTP.ShutdownTimeout > 0, "http.shutdown_timeout", "must be positive, got %s", c.HTTP.ShutdownTimeout)
+	for _, proxy := range c.HTTP.TrustedProxies {
+		v.check(isIPOrCIDR(proxy), "http.trusted_proxies", "must be an IP or a CIDR, got %q", proxy)
+	}
+
+	v.checkPG(c.PG)
+
+	switch strings.ToLower(c.Log.Level) {
+	case "debug", "info", "warn", "error":
+	default:
+		v.check(false, "logger.log_level", "must be one of debug, info, warn, error, got %q", c.Log.Level)
+	}
+
+	if c.Auth.Enabled {
+		v.check(len(c.Auth.APIKeys) > 0 || c.Auth.JWTSecret != "", "auth", "api_keys or jwt_secret must be set when auth is enabled")
+
+		for key, role := range c.Auth.APIKeys {
+			v.check(role == "admin" || role == "dispatcher" || role == "integration", "auth.api_keys", "key %s...: role must be admin, dispatcher or integration, got %q", keyPrefix(key), role)
+		}
+	}
+
+	if c.RateLimit.Enabled {
+		v.check(c.RateLimit.Store == "memory" || c.RateLimit.Store == "postgres", "rate_limit.store", "must be memory or postgres, got %q", c.RateLimit.Store)
+		v.check(c.RateLimit.RPS > 0, "rate_limit.rps", "must be positive, got %v", c.RateLimit.RPS)
+		v.check(c.RateLimit.Burst > 0, "rate_limit.burst", "must be positive, got %d", c.RateLimit.Burst)
+		v.check(c.RateLimit.Idle > 0, "rate_limit.idle", "must be positive, got %s", c.RateLimit.Idle)
+		v.check(c.RateLimit.IPRPS > 0, "rate_limit.ip_rps", "must be positive, got %v", c.RateLimit.IPRPS)
+		v.check(c.RateLimit.IPBurst > 0, "rate_limit.ip_burst", "must be positive, got %d", c.RateLimit.IPBurst)
+
+		for i, route := range c.RateLimit.Routes {
+			field := fmt.Sprintf("rate_limit.routes[%d]", i)
+			v.check(isHTTPMethod(route.Method), field+".method", "unknown method %q", route.Method)
+			v.check(strings.HasPrefix(route.Path, "/"), field+".path", "must start with /, got %q", route.Path)
+			v.check(route.RPS > 0, field+".rps", "must be positive, got %v", route.RPS)
+			v.check(route.Burst > 0, field+".burst", "must be positive, got %d", route.Burst)
+		}
+	}
+
+	if c.Tracing.Enabled {
+		v.check(c.Tracing.Exporter == "stdout" || c.Tracing.Exporter == "otlp", "tracing.exporter", "must be stdout or otlp, got %q", c.Tracing.Exporter)
+		v.check(c.Tracing.Exporter != "otlp" || c.Tracing.Endpoint != "", "tracing.endpoint", "must be set for the otlp exporter")
+		v.check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio", "must be in 0..1, got %v", c.Tracing.SampleRatio)
+	}
+
+	return v.err()
+}
+
+// ValidatePG checks only the Postgres section, the one the migrate
+// subcommand needs.
+func (c *Config) ValidatePG() error {
+	v := &validator{}
+	v.checkPG(c.PG)
+
+	return v.err()
+}
+
+func (v *validator) checkPG(pg PG) {
+	pgURL, err := url.Parse(pg.URL)
+	v.check(err == nil && (pgURL.Scheme == "postgres" || pgURL.Scheme == "postgresql"), "postgres.pg_url", "must be a postgres:// URL")
+	v.check(pg.PoolMax > 0, "postgres.pool_max", "must be positive, got %d", pg.PoolMax)
+	v.check(pg.PoolMin >= 0 && pg.PoolMin <= pg.PoolMax, "postgres.pool_min", "must be in 0..pool_max, got %d", pg.PoolMin)
+	v.check(pg.ConnAttempts > 0, "postgres.conn_attempts", "must be positive, got %d", pg.ConnAttempts)
+	v.check(pg.ConnTimeout > 0, "postgres.conn_timeout", "must be positive, got %s", pg.ConnTimeout)
+	v.check(pg.StatementTimeout >= 0, "postgres.statement_timeout", "can't be negative, got %s", pg.StatementTimeout)
+}
+
+func (v *validator) err() error {
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+
+	return nil
+}
+
+// keyPrefix shows only the start of an api key in the report.
+func keyPrefix(key string) string {
+	if len(key) > 4 {
+		return key[:4]
+	}
+
+	return key
+}
+
+func isHTTPMethod(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
+
+func isIPOrCIDR(s string) bool {
+	if _, _, err := net.ParseCIDR(s); err == nil {
+		return true
+	}
+
+	return net.ParseIP(s) != nil
+}
@@ -1,6 +1,7 @@
 package integration_tests
 
 import (
+	"fmt"
 	"log"
 	"net/http"
 	"os"
@@ -29,6 +30,10 @@ func TestMain(m *testing.M) {
 
 	log.Printf("Integration tests: host %s is available", host)
 
+	if err := createRegions(1, 2, 3, 4); err != nil {
+		log.Fatalf("Integration tests: failed to create the regions: %s", err)
+	}
+
 	code := m.Run()
 
 	os.Exit(code)
@@ -53,6 +58,24 @@ func healthCheck(attempts int) error {
 	return err
 }
 
+// createRegions adds the regions the couriers and orders of the tests
+// refer to into the catalog.
+func createRegions(ids ...int) error {
+	for _, id := range ids {
+		err := Do(
+			Put(fmt.Sprintf("%s/regions/%d", basePath, id)),
+			Send().Headers("Content-Type").Add("application/json"),
+			Send().Body().String(fmt.Sprintf(`{"name": "Region %d"}`, id)),
+			Expect().Status().Equal(http.StatusOK),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // HTTP /couriers:
 
 // HTTP GET: /couriers
@@ -1,36 +1,204 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/almostinf/order_delivery_service/config"
+	"github.com/almostinf/order_delivery_service/internal/auth"
 	v1 "github.com/almostinf/order_delivery_service/internal/controller/http/v1"
+	"github.com/almostinf/order_delivery_service/internal/health"
 	"github.com/almostinf/order_delivery_service/internal/infrastructure/repository"
+	"github.com/almostinf/order_delivery_service/internal/metrics"
+	"github.com/almostinf/order_delivery_service/internal/ratelimit"
+	"github.com/almostinf/order_delivery_service/internal/tracing"
 	"github.com/almostinf/order_delivery_service/internal/usecase"
+	"github.com/almostinf/order_delivery_service/pkg/httpserver"
 	"github.com/almostinf/order_delivery_service/pkg/logger"
 	"github.com/almostinf/order_delivery_service/pkg/postgres"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // Run creates objects via constructors.
 func Run(cfg *config.Config) {
 	l := logger.New(cfg.Log.Level)
 
-	pg, err := postgres.New(cfg.PG.URL)
+	if cfg.PG.AutoMigrate {
+		if err := Migrate(l, cfg.PG.URL, "up"); err != nil {
+			l.Fatal(fmt.Errorf("app - Run - Migrate: %w", err))
+		}
+		l.Info("app - Run - the migrations up attempt was successful")
+	}
+
+	// Spans stay no-op until the tracer provider is set up
+	shutdownTracing := func(context.Context) error { return nil }
+	if cfg.Tracing.Enabled {
+		var err error
+		shutdownTracing, err = tracing.Setup(context.Background(), tracing.Config{
+			ServiceName:    cfg.App.Name,
+			ServiceVersion: cfg.App.Version,
+			Exporter:       cfg.Tracing.Exporter,
+			Endpoint:       cfg.Tracing.Endpoint,
+			Insecure:       cfg.Tracing.Insecure,
+			SampleRatio:    cfg.Tracing.SampleRatio,
+		})
+		if err != nil {
+			l.Fatal(fmt.Errorf("app - Run - tracing.Setup: %w", err))
+		}
+	}
+
+	pg, err := postgres.New(cfg.PG.URL,
+		postgres.MaxPoolSize(cfg.PG.PoolMax),
+		postgres.MinPoolSize(cfg.PG.PoolMin),
+		postgres.ConnAttempts(cfg.PG.ConnAttempts),
+		postgres.ConnTimeout(cfg.PG.ConnTimeout),
+		postgres.StatementTimeout(cfg.PG.StatementTimeout),
+		postgres.Tracer(tracing.QueryTracer{}),
+	)
 	if err != nil {
 		l.Fatal(fmt.Errorf("app - Run - postgres.New: %w", err))
 	}
-	defer pg.Close()
+
+	prometheus.MustRegister(metrics.NewPoolCollector(pg.Pool))
 
 	courierRepo := repository.NewCourierRepo(pg)
 	orderRepo := repository.NewOrderRepo(pg)
+	analyticsRepo := repository.NewAnalyticsRepo(pg)
+	regionRepo := repository.NewRegionRepo(pg)
 
 	courierUseCase := usecase.NewCourierUseCase(courierRepo)
 	orderUseCase := usecase.NewOrderUseCase(orderRepo)
+	analyticsUseCase := usecase.NewAnalyticsUseCase(analyticsRepo)
+	regionUseCase := usecase.NewRegionUseCase(regionRepo)
+
+	authenticator, err := newAuthenticator(cfg.Auth)
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - newAuthenticator: %w", err))
+	}
+
+	// The background jobs stop before the pool is closed
+	background, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+
+	ipRateLimiter, rateLimiter, err := newRateLimiters(background, cfg.RateLimit, pg, l)
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - newRateLimiters: %w", err))
+	}
 
+	migrationVersion, err := expectedMigrationVersion()
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - expectedMigrationVersion: %w", err))
+	}
+
+	checkers := []health.Checker{
+		health.NewPostgresChecker(pg),
+		health.NewMigrationChecker(pg, migrationVersion),
+	}
+
+	// HTTP Server
 	handler := gin.New()
-	v1.NewRouter(handler, l, *courierUseCase, *orderUseCase)
-	if err := handler.Run(":8080"); err != nil {
-		l.Fatal(fmt.Errorf("app - Run - handler.New: %w", err))
+	if err := handler.SetTrustedProxies(cfg.HTTP.TrustedProxies); err != nil {
+		l.Fatal(fmt.Errorf("app - Run - handler.SetTrustedProxies: %w", err))
+	}
+	v1.NewRouter(handler, l, authenticator, ipRateLimiter, rateLimiter, checkers, *courierUseCase, *orderUseCase, *analyticsUseCase, *regionUseCase)
+	httpServer := httpserver.New(handler,
+		httpserver.Port(cfg.HTTP.Port),
+		httpserver.ReadTimeout(cfg.HTTP.ReadTimeout),
+		httpserver.WriteTimeout(cfg.HTTP.WriteTimeout),
+		httpserver.IdleTimeout(cfg.HTTP.IdleTimeout),
+		httpserver.ShutdownTimeout(cfg.HTTP.ShutdownTimeout),
+	)
+	l.Info("app - Run - http server is listening on port " + cfg.HTTP.Port)
+
+	// Waiting signal
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+
+	select {
+	case s := <-interrupt:
+		l.Info("app - Run - signal: " + s.String())
+	case err = <-httpServer.Notify():
+		l.Error(fmt.Errorf("app - Run - httpServer.Notify: %w", err))
+	}
+
+	// Shutdown
+	l.Info("app - Run - draining http connections")
+	if err := httpServer.Shutdown(); err != nil {
+		l.Error(fmt.Errorf("app - Run - httpServer.Shutdown: %w", err))
+	}
+
+	l.Info("app - Run - closing postgres pool")
+	stopBackground()
+	pg.Close()
+
+	l.Info("app - Run - flushing traces")
+	if err := shutdownTracing(context.Background()); err != nil {
+		l.Error(fmt.Errorf("app - Run - shutdownTracing: %w", err))
+	}
+
+	l.Info("app - Run - shutdown completed")
+}
+
+// newAuthenticator returns nil when authentication is disabled, so every
+// route stays public.
+func newAuthenticator(cfg config.Auth) (auth.Authenticator, error) {
+	if !cfg.Enabled {
+		return nil, nil
 	}
+
+	var chain auth.Chain
+
+	if len(cfg.APIKeys) > 0 {
+		apiKeys, err := auth.NewAPIKeyAuthenticator(cfg.APIKeys)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, apiKeys)
+	}
+
+	if cfg.JWTSecret != "" {
+		chain = append(chain, auth.NewJWTAuthenticator([]byte(cfg.JWTSecret)))
+	}
+
+	if len(chain) == 0 {
+		return nil, errors.New("authentication is enabled but neither api_keys nor jwt_secret is set")
+	}
+
+	return chain, nil
+}
+
+// newRateLimiters returns the IP limiter checked before authentication and
+// the client limiter checked after it, nil when rate limiting is disabled.
+// The postgres store sweeps its idle buckets until the context is done.
+func newRateLimiters(ctx context.Context, cfg config.RateLimit, pg *postgres.Postgres, l logger.Interface) (gin.HandlerFunc, gin.HandlerFunc, error) {
+	if !cfg.Enabled {
+		return nil, nil, nil
+	}
+
+	var store ratelimit.Store
+
+	switch cfg.Store {
+	case "memory":
+		store = ratelimit.NewMemoryStore(cfg.Idle)
+	case "postgres":
+		pgStore := ratelimit.NewPostgresStore(pg, cfg.Idle)
+		go pgStore.Sweep(ctx, l)
+		store = pgStore
+	default:
+		return nil, nil, fmt.Errorf("unknown rate limit store %q", cfg.Store)
+	}
+
+	routes := make(map[string]ratelimit.Rule, len(cfg.Routes))
+	for _, route := range cfg.Routes {
+		routes[strings.ToUpper(route.Method)+" "+route.Path] = ratelimit.Rule{RPS: route.RPS, Burst: route.Burst}
+	}
+
+	return v1.NewIPRateLimiterMiddleware(store, ratelimit.Rule{RPS: cfg.IPRPS, Burst: cfg.IPBurst}),
+		v1.NewRateLimiterMiddleware(store, ratelimit.Rule{RPS: cfg.RPS, Burst: cfg.Burst}, routes), nil
 }
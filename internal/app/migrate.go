@@ -1,12 +1,16 @@
 package app
 
 import (
+	"context"
 	"database/sql"
-	"log"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"time"
 
+	"github.com/almostinf/order_delivery_service/migrations"
+	"github.com/almostinf/order_delivery_service/pkg/logger"
 	_ "github.com/lib/pq"
 	"github.com/pressly/goose"
 )
@@ -14,14 +18,18 @@ import (
 const (
 	_defaultAttempts = 5
 	_defaultTimeout  = time.Second
+
+	// _migrationLockID keys the advisory lock the replicas migrating on
+	// startup take one after another, goose doesn't lock by itself
+	_migrationLockID = 5_031_942_118
 )
 
-func init() {
-	databaseURL, ok := os.LookupEnv("PG_URL")
-	if !ok || len(databaseURL) == 0 {
-		log.Fatalf("migrate: environment variable not declared: PG_URL")
-	}
+// MigrateCommands are the goose commands the migrate subcommand supports.
+var MigrateCommands = []string{"up", "down", "status", "redo", "version"}
 
+// Migrate runs the goose command against the embedded migrations. The
+// command holds an advisory lock, so concurrent runs wait for each other.
+func Migrate(l logger.Interface, databaseURL string, command string, args ...string) error {
 	var (
 		attempts = _defaultAttempts
 		err      error
@@ -30,31 +38,94 @@ func init() {
 
 	for attempts > 0 {
 		db, err = sql.Open("postgres", databaseURL)
+		if err == nil {
+			err = db.Ping()
+		}
 		if err == nil {
 			break
 		}
-		log.Printf("migrate: postgres is trying to connect, attempts left: %d", attempts)
+		l.Info("app - Migrate - postgres is trying to connect, attempts left: %d", attempts)
 		time.Sleep(_defaultTimeout)
 		attempts--
 	}
 
 	if err != nil {
-		log.Fatalf("failed to connect to database: %v", err)
+		return fmt.Errorf("app - Migrate - failed to connect to database: %w", err)
 	}
-
 	defer db.Close()
 
-	// Get the current directory
-	dir, err := os.Getwd()
+	// goose reads the migrations from a directory only
+	dir, err := os.MkdirTemp("", "migrations")
+	if err != nil {
+		return fmt.Errorf("app - Migrate - os.MkdirTemp: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := extractMigrations(dir); err != nil {
+		return fmt.Errorf("app - Migrate - %w", err)
+	}
+
+	// The lock belongs to the session, so it's taken on a connection of its
+	// own while goose uses the others
+	ctx := context.Background()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("app - Migrate - db.Conn: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", _migrationLockID); err != nil {
+		return fmt.Errorf("app - Migrate - pg_advisory_lock: %w", err)
+	}
+	defer conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", _migrationLockID) //nolint:errcheck // released with the session anyway
+
+	if err := goose.Run(command, db, dir, args...); err != nil {
+		return fmt.Errorf("app - Migrate - goose.Run(%s): %w", command, err)
+	}
+
+	return nil
+}
+
+func extractMigrations(dir string) error {
+	files, err := fs.Glob(migrations.FS, "*.sql")
+	if err != nil {
+		return fmt.Errorf("extractMigrations - fs.Glob: %w", err)
+	}
+
+	for _, file := range files {
+		data, err := migrations.FS.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("extractMigrations - migrations.FS.ReadFile: %w", err)
+		}
+
+		if err := os.WriteFile(filepath.Join(dir, file), data, 0o600); err != nil {
+			return fmt.Errorf("extractMigrations - os.WriteFile: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// expectedMigrationVersion returns the version of the latest migration the
+// binary is shipped with.
+func expectedMigrationVersion() (int64, error) {
+	files, err := fs.Glob(migrations.FS, "*.sql")
 	if err != nil {
-		log.Fatalf("failed to get current directory: %v", err)
+		return 0, err
 	}
 
-	migrationsDir := filepath.Join(dir, "migrations")
+	var latest int64
+	for _, file := range files {
+		version, err := goose.NumericComponent(file)
+		if err != nil {
+			return 0, err
+		}
 
-	if err := goose.Up(db, migrationsDir); err != nil {
-		log.Fatalf("failed to run migrations: %v", err)
+		if version > latest {
+			latest = version
+		}
 	}
 
-	log.Println("the migrations up attempt was successful")
+	return latest, nil
 }
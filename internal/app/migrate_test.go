@@ -0,0 +1,64 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/almostinf/order_delivery_service/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractMigrations(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, extractMigrations(dir))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	require.NoError(t, err)
+
+	expected, err := filepath.Glob(filepath.Join("..", "..", "migrations", "*.sql"))
+	require.NoError(t, err)
+	require.Len(t, files, len(expected))
+
+	for _, file := range expected {
+		_, err := os.Stat(filepath.Join(dir, filepath.Base(file)))
+		require.NoError(t, err)
+	}
+}
+
+func TestExpectedMigrationVersion(t *testing.T) {
+	t.Parallel()
+
+	files, err := filepath.Glob(filepath.Join("..", "..", "migrations", "*.sql"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	latest := filepath.Base(files[len(files)-1])
+
+	version, err := expectedMigrationVersion()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(latest, strconv.FormatInt(version, 10)+"_"), latest)
+}
+
+func TestMigrateConcurrently(t *testing.T) {
+	t.Parallel()
+
+	url := os.Getenv("PG_URL")
+	if url == "" {
+		t.Skip("PG_URL is not set")
+	}
+
+	// Replicas starting together migrate one after another
+	errs := make(chan error, 4)
+	for i := 0; i < cap(errs); i++ {
+		go func() { errs <- Migrate(logger.New("error"), url, "up") }()
+	}
+
+	for i := 0; i < cap(errs); i++ {
+		require.NoError(t, <-errs)
+	}
+}
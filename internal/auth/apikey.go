@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+type apiKey struct {
+	hash [sha256.Size]byte
+	role Role
+}
+
+// APIKeyAuthenticator accepts the static keys of trusted clients passed in
+// the X-API-Key header.
+type APIKeyAuthenticator struct {
+	keys []apiKey
+}
+
+// NewAPIKeyAuthenticator creates the authenticator from the mapping
+// key -> role. Courier role can't be given to a key, couriers are
+// authenticated with tokens that carry their id.
+func NewAPIKeyAuthenticator(keys map[string]string) (*APIKeyAuthenticator, error) {
+	a := &APIKeyAuthenticator{}
+
+	for key, roleStr := range keys {
+		role := Role(roleStr)
+		if !role.Valid() || role == RoleCourier {
+			return nil, fmt.Errorf("auth - NewAPIKeyAuthenticator - invalid role %q", roleStr)
+		}
+
+		a.keys = append(a.keys, apiKey{hash: sha256.Sum256([]byte(key)), role: role})
+	}
+
+	return a, nil
+}
+
+func (a *APIKeyAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	key := r.Header.Get(APIKeyHeader)
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+
+	hash := sha256.Sum256([]byte(key))
+	for _, k := range a.keys {
+		if subtle.ConstantTimeCompare(hash[:], k.hash[:]) == 1 {
+			return &Principal{
+				Subject: "api_key:" + hex.EncodeToString(hash[:4]),
+				Role:    k.role,
+			}, nil
+		}
+	}
+
+	return nil, ErrInvalidCredentials
+}
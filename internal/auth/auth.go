@@ -0,0 +1,77 @@
+// Package auth authenticates API clients and describes the roles they can
+// act as.
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+type Role string
+
+const (
+	RoleAdmin       Role = "admin"
+	RoleDispatcher  Role = "dispatcher"
+	RoleCourier     Role = "courier"
+	RoleIntegration Role = "integration"
+)
+
+func (r Role) Valid() bool {
+	switch r {
+	case RoleAdmin, RoleDispatcher, RoleCourier, RoleIntegration:
+		return true
+	}
+
+	return false
+}
+
+var (
+	// ErrNoCredentials is returned when the request doesn't carry the
+	// credentials the authenticator checks, so the next one can be tried.
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrInvalidCredentials is returned when the credentials are present
+	// but can't be accepted.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Principal is the authenticated client of a request. CourierID is set for
+// the courier role only.
+type Principal struct {
+	Subject   string
+	Role      Role
+	CourierID uuid.UUID
+}
+
+// HasRole reports whether the principal acts as one of the roles.
+func (p *Principal) HasRole(roles ...Role) bool {
+	for _, role := range roles {
+		if p.Role == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// Chain tries the authenticators in order until one of them finds its
+// credentials in the request.
+type Chain []Authenticator
+
+func (c Chain) Authenticate(r *http.Request) (*Principal, error) {
+	for _, a := range c {
+		principal, err := a.Authenticate(r)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+
+		return principal, err
+	}
+
+	return nil, ErrNoCredentials
+}
@@ -0,0 +1,163 @@
+package auth_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/almostinf/order_delivery_service/internal/auth"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWTAuthenticator(t *testing.T) {
+	t.Parallel()
+
+	secret := []byte("secret")
+	courierID := uuid.New()
+	exp := time.Now().Add(time.Hour).Unix()
+
+	sign := func(secret []byte, claims auth.Claims) string {
+		token, err := auth.NewJWTAuthenticator(secret).Sign(claims)
+		require.NoError(t, err)
+
+		return token
+	}
+
+	testcases := []struct {
+		name        string
+		header      string
+		expected    *auth.Principal
+		expectedErr error
+	}{
+		{
+			name:     "courier",
+			header:   "Bearer " + sign(secret, auth.Claims{Subject: "c1", Role: auth.RoleCourier, CourierID: courierID, ExpiresAt: exp}),
+			expected: &auth.Principal{Subject: "c1", Role: auth.RoleCourier, CourierID: courierID},
+		},
+		{
+			name:     "dispatcher",
+			header:   "Bearer " + sign(secret, auth.Claims{Subject: "d1", Role: auth.RoleDispatcher, ExpiresAt: exp}),
+			expected: &auth.Principal{Subject: "d1", Role: auth.RoleDispatcher},
+		},
+		{
+			name:        "no header",
+			expectedErr: auth.ErrNoCredentials,
+		},
+		{
+			name:        "wrong secret",
+			header:      "Bearer " + sign([]byte("other"), auth.Claims{Subject: "d1", Role: auth.RoleDispatcher, ExpiresAt: exp}),
+			expectedErr: auth.ErrInvalidCredentials,
+		},
+		{
+			name:        "expired",
+			header:      "Bearer " + sign(secret, auth.Claims{Subject: "d1", Role: auth.RoleDispatcher, ExpiresAt: time.Now().Add(-time.Minute).Unix()}),
+			expectedErr: auth.ErrInvalidCredentials,
+		},
+		{
+			name:        "unknown role",
+			header:      "Bearer " + sign(secret, auth.Claims{Subject: "d1", Role: "root", ExpiresAt: exp}),
+			expectedErr: auth.ErrInvalidCredentials,
+		},
+		{
+			name:        "courier without courier id",
+			header:      "Bearer " + sign(secret, auth.Claims{Subject: "c1", Role: auth.RoleCourier, ExpiresAt: exp}),
+			expectedErr: auth.ErrInvalidCredentials,
+		},
+		{
+			name:        "unsigned token",
+			header:      "Bearer eyJhbGciOiJub25lIn0.eyJyb2xlIjoiYWRtaW4ifQ.",
+			expectedErr: auth.ErrInvalidCredentials,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			req, err := http.NewRequest(http.MethodGet, "/", nil)
+			require.NoError(t, err)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+
+			principal, err := auth.NewJWTAuthenticator(secret).Authenticate(req)
+
+			require.ErrorIs(t, err, tc.expectedErr)
+			require.Equal(t, tc.expected, principal)
+		})
+	}
+}
+
+func TestChain(t *testing.T) {
+	t.Parallel()
+
+	apiKeys, err := auth.NewAPIKeyAuthenticator(map[string]string{"key": "integration"})
+	require.NoError(t, err)
+
+	jwt := auth.NewJWTAuthenticator([]byte("secret"))
+	token, err := jwt.Sign(auth.Claims{Subject: "a1", Role: auth.RoleAdmin, ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	require.NoError(t, err)
+
+	chain := auth.Chain{apiKeys, jwt}
+
+	testcases := []struct {
+		name         string
+		headers      map[string]string
+		expectedRole auth.Role
+		expectedErr  error
+	}{
+		{
+			name:         "api key",
+			headers:      map[string]string{auth.APIKeyHeader: "key"},
+			expectedRole: auth.RoleIntegration,
+		},
+		{
+			name:         "token",
+			headers:      map[string]string{"Authorization": "Bearer " + token},
+			expectedRole: auth.RoleAdmin,
+		},
+		{
+			name:        "unknown api key",
+			headers:     map[string]string{auth.APIKeyHeader: "other", "Authorization": "Bearer " + token},
+			expectedErr: auth.ErrInvalidCredentials,
+		},
+		{
+			name:        "no credentials",
+			expectedErr: auth.ErrNoCredentials,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			req, err := http.NewRequest(http.MethodGet, "/", nil)
+			require.NoError(t, err)
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+
+			principal, err := chain.Authenticate(req)
+
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedRole, principal.Role)
+		})
+	}
+}
+
+func TestNewAPIKeyAuthenticatorRejectsCourierRole(t *testing.T) {
+	t.Parallel()
+
+	_, err := auth.NewAPIKeyAuthenticator(map[string]string{"key": "courier"})
+	require.Error(t, err)
+}
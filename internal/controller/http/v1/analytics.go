@@ -0,0 +1,271 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/almostinf/order_delivery_service/internal/auth"
+	"github.com/almostinf/order_delivery_service/internal/entity"
+	"github.com/almostinf/order_delivery_service/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+type analyticsRoutes struct {
+	uc usecase.AnalyticsUseCase
+}
+
+func newAnalyticsRoutes(handler *gin.RouterGroup, z *authorizer, uc usecase.AnalyticsUseCase) {
+	r := &analyticsRoutes{uc}
+
+	h := handler.Group("/analytics", z.allow(auth.RoleAdmin, auth.RoleDispatcher))
+	{
+		h.GET("/couriers", r.getCouriers)
+		h.GET("/orders", r.getOrders)
+	}
+}
+
+func ValidateCourierAnalyticsFilter(filter entity.CourierAnalyticsFilter) error {
+	if filter.EndDate.Sub(filter.StartDate) <= 0 {
+		return errors.New("the end_date must not be less or equal than the start_date")
+	}
+
+	if filter.Region != nil && *filter.Region < 0 {
+		return errors.New("the region can't be less than zero")
+	}
+
+	if filter.CourierType != "" && filter.CourierType != "FOOT" && filter.CourierType != "BIKE" && filter.CourierType != "AUTO" {
+		return errors.New("invalid courier type format")
+	}
+
+	switch filter.SortBy {
+	case "rating", "earnings", "completed_orders", "on_time_rate":
+	default:
+		return errors.New("invalid sort_by format")
+	}
+
+	if filter.Limit <= 0 || filter.Offset < 0 {
+		return errors.New("wrong limit or offset format")
+	}
+
+	return nil
+}
+
+type getCouriersAnalyticsResponse struct {
+	StartDate time.Time                  `json:"start_date"`
+	EndDate   time.Time                  `json:"end_date"`
+	Couriers  []*entity.CourierAnalytics `json:"couriers"`
+	Limit     int                        `json:"limit"`
+	Offset    int                        `json:"offset"`
+}
+
+// @Summary     Get Couriers Leaderboard
+// @Description Get couriers ranked by rating, earnings, completed orders or on-time rate over a period
+// @ID          get-couriers-analytics
+// @Tags  	    analytics
+// @Produce     json
+// @Param       start_date query string true "Start Date, inclusive (YYYY-MM-DD)"
+// @Param       end_date query string true "End Date, exclusive (YYYY-MM-DD)"
+// @Param       region query int false "Region served by the courier"
+// @Param       courier_type query string false "Courier type (FOOT, BIKE, AUTO)"
+// @Param       sort_by query string false "Sort by rating, earnings, completed_orders or on_time_rate (default: rating)"
+// @Param       limit query int false "Limit the number of results (default: 10)"
+// @Param       offset query int false "Offset the list of results (default: 0)"
+// @Success     200 {object} getCouriersAnalyticsResponse
+// @Failure     400 {object} response
+// @Failure     401 {object} response
+// @Failure     403 {object} response
+// @Failure     500 {object} response
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
+// @Router      /analytics/couriers [get]
+func (r *analyticsRoutes) getCouriers(c *gin.Context) {
+	filter := entity.CourierAnalyticsFilter{
+		CourierType: c.Query("courier_type"),
+		SortBy:      c.DefaultQuery("sort_by", "rating"),
+		Limit:       10,
+		Offset:      0,
+	}
+
+	layout := "2006-01-02"
+	startDate, err := time.Parse(layout, c.Query("start_date"))
+	if err != nil {
+		requestLogger(c).Error(err, "http - v1 - analytics - getCouriers - time.Parse(start_date)")
+		errorResponse(c, http.StatusBadRequest, "failed conversation start_date to time")
+
+		return
+	}
+	filter.StartDate = startDate
+
+	endDate, err := time.Parse(layout, c.Query("end_date"))
+	if err != nil {
+		requestLogger(c).Error(err, "http - v1 - analytics - getCouriers - time.Parse(end_date)")
+		errorResponse(c, http.StatusBadRequest, "failed conversation end_date to time")
+
+		return
+	}
+	filter.EndDate = endDate
+
+	if regionStr, ok := c.GetQuery("region"); ok {
+		region, err := strconv.Atoi(regionStr)
+		if err != nil {
+			requestLogger(c).Error(err, "http - v1 - analytics - getCouriers - strconv.Atoi(region)")
+			errorResponse(c, http.StatusBadRequest, "failed conversation region to int")
+
+			return
+		}
+		filter.Region = &region
+	}
+
+	if limitStr, ok := c.GetQuery("limit"); ok {
+		filter.Limit, err = strconv.Atoi(limitStr)
+		if err != nil {
+			requestLogger(c).Error(err, "http - v1 - analytics - getCouriers - strconv.Atoi(limit)")
+			errorResponse(c, http.StatusBadRequest, "failed conversation limit to int")
+
+			return
+		}
+	}
+
+	if offsetStr, ok := c.GetQuery("offset"); ok {
+		filter.Offset, err = strconv.Atoi(offsetStr)
+		if err != nil {
+			requestLogger(c).Error(err, "http - v1 - analytics - getCouriers - strconv.Atoi(offset)")
+			errorResponse(c, http.StatusBadRequest, "failed conversation offset to int")
+
+			return
+		}
+	}
+
+	if err := ValidateCourierAnalyticsFilter(filter); err != nil {
+		requestLogger(c).Error(err, "http - v1 - analytics - getCouriers")
+		errorResponse(c, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	couriers, err := r.uc.GetCouriersAnalytics(c.Request.Context(), filter)
+	if err != nil {
+		requestLogger(c).Error(err, "http - v1 - analytics - getCouriers - GetCouriersAnalytics")
+		errorResponseFromError(c, err, "analytics service problems")
+
+		return
+	}
+
+	response := getCouriersAnalyticsResponse{
+		StartDate: filter.StartDate,
+		EndDate:   filter.EndDate,
+		Couriers:  couriers,
+		Limit:     filter.Limit,
+		Offset:    filter.Offset,
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func ValidateOrderAnalyticsFilter(filter entity.OrderAnalyticsFilter) error {
+	if filter.EndDate.Sub(filter.StartDate) <= 0 {
+		return errors.New("the end_date must not be less or equal than the start_date")
+	}
+
+	if filter.Bucket != "day" && filter.Bucket != "week" {
+		return errors.New("invalid bucket format")
+	}
+
+	if filter.Region != nil && *filter.Region < 0 {
+		return errors.New("the region can't be less than zero")
+	}
+
+	if filter.CourierType != "" && filter.CourierType != "FOOT" && filter.CourierType != "BIKE" && filter.CourierType != "AUTO" {
+		return errors.New("invalid courier type format")
+	}
+
+	return nil
+}
+
+type getOrdersAnalyticsResponse struct {
+	StartDate time.Time                `json:"start_date"`
+	EndDate   time.Time                `json:"end_date"`
+	Bucket    string                   `json:"bucket"`
+	Orders    []*entity.OrderAnalytics `json:"orders"`
+}
+
+// @Summary     Get Orders Analytics
+// @Description Get order volumes, completion times and SLA breaches bucketed by day or week, region and courier type
+// @ID          get-orders-analytics
+// @Tags  	    analytics
+// @Produce     json
+// @Param       start_date query string true "Start Date, inclusive (YYYY-MM-DD)"
+// @Param       end_date query string true "End Date, exclusive (YYYY-MM-DD)"
+// @Param       bucket query string false "Bucket size: day or week (default: day)"
+// @Param       region query int false "Order region"
+// @Param       courier_type query string false "Courier type (FOOT, BIKE, AUTO)"
+// @Success     200 {object} getOrdersAnalyticsResponse
+// @Failure     400 {object} response
+// @Failure     401 {object} response
+// @Failure     403 {object} response
+// @Failure     500 {object} response
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
+// @Router      /analytics/orders [get]
+func (r *analyticsRoutes) getOrders(c *gin.Context) {
+	filter := entity.OrderAnalyticsFilter{
+		Bucket:      c.DefaultQuery("bucket", "day"),
+		CourierType: c.Query("courier_type"),
+	}
+
+	layout := "2006-01-02"
+	startDate, err := time.Parse(layout, c.Query("start_date"))
+	if err != nil {
+		requestLogger(c).Error(err, "http - v1 - analytics - getOrders - time.Parse(start_date)")
+		errorResponse(c, http.StatusBadRequest, "failed conversation start_date to time")
+
+		return
+	}
+	filter.StartDate = startDate
+
+	endDate, err := time.Parse(layout, c.Query("end_date"))
+	if err != nil {
+		requestLogger(c).Error(err, "http - v1 - analytics - getOrders - time.Parse(end_date)")
+		errorResponse(c, http.StatusBadRequest, "failed conversation end_date to time")
+
+		return
+	}
+	filter.EndDate = endDate
+
+	if regionStr, ok := c.GetQuery("region"); ok {
+		region, err := strconv.Atoi(regionStr)
+		if err != nil {
+			requestLogger(c).Error(err, "http - v1 - analytics - getOrders - strconv.Atoi(region)")
+			errorResponse(c, http.StatusBadRequest, "failed conversation region to int")
+
+			return
+		}
+		filter.Region = &region
+	}
+
+	if err := ValidateOrderAnalyticsFilter(filter); err != nil {
+		requestLogger(c).Error(err, "http - v1 - analytics - getOrders")
+		errorResponse(c, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	orders, err := r.uc.GetOrdersAnalytics(c.Request.Context(), filter)
+	if err != nil {
+		requestLogger(c).Error(err, "http - v1 - analytics - getOrders - GetOrdersAnalytics")
+		errorResponseFromError(c, err, "analytics service problems")
+
+		return
+	}
+
+	response := getOrdersAnalyticsResponse{
+		StartDate: filter.StartDate,
+		EndDate:   filter.EndDate,
+		Bucket:    filter.Bucket,
+		Orders:    orders,
+	}
+
+	c.JSON(http.StatusOK, response)
+}
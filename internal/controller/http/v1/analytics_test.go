@@ -0,0 +1,191 @@
+package v1_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	v1 "github.com/almostinf/order_delivery_service/internal/controller/http/v1"
+	"github.com/almostinf/order_delivery_service/internal/entity"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCourierAnalyticsFilter(t *testing.T) {
+	t.Parallel()
+
+	startDate := time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2023, 4, 8, 0, 0, 0, 0, time.UTC)
+	region, negativeRegion := 1, -1
+
+	testcases := []struct {
+		name        string
+		in          entity.CourierAnalyticsFilter
+		expectedErr error
+	}{
+		{
+			name: "success",
+			in: entity.CourierAnalyticsFilter{
+				StartDate:   startDate,
+				EndDate:     endDate,
+				Region:      &region,
+				CourierType: "BIKE",
+				SortBy:      "earnings",
+				Limit:       10,
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "wrong period",
+			in: entity.CourierAnalyticsFilter{
+				StartDate: endDate,
+				EndDate:   startDate,
+				SortBy:    "rating",
+			},
+			expectedErr: errors.New("the end_date must not be less or equal than the start_date"),
+		},
+		{
+			name: "wrong region",
+			in: entity.CourierAnalyticsFilter{
+				StartDate: startDate,
+				EndDate:   endDate,
+				Region:    &negativeRegion,
+				SortBy:    "rating",
+			},
+			expectedErr: errors.New("the region can't be less than zero"),
+		},
+		{
+			name: "wrong courier type",
+			in: entity.CourierAnalyticsFilter{
+				StartDate:   startDate,
+				EndDate:     endDate,
+				CourierType: "BOAT",
+				SortBy:      "rating",
+			},
+			expectedErr: errors.New("invalid courier type format"),
+		},
+		{
+			name: "wrong sort by",
+			in: entity.CourierAnalyticsFilter{
+				StartDate: startDate,
+				EndDate:   endDate,
+				SortBy:    "courier_id",
+			},
+			expectedErr: errors.New("invalid sort_by format"),
+		},
+		{
+			name: "wrong limit",
+			in: entity.CourierAnalyticsFilter{
+				StartDate: startDate,
+				EndDate:   endDate,
+				SortBy:    "rating",
+				Limit:     -1,
+			},
+			expectedErr: errors.New("wrong limit or offset format"),
+		},
+		{
+			name: "zero limit",
+			in: entity.CourierAnalyticsFilter{
+				StartDate: startDate,
+				EndDate:   endDate,
+				SortBy:    "rating",
+				Limit:     0,
+			},
+			expectedErr: errors.New("wrong limit or offset format"),
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := v1.ValidateCourierAnalyticsFilter(tc.in)
+
+			if tc.expectedErr != nil {
+				require.EqualError(t, err, tc.expectedErr.Error())
+			} else {
+				require.ErrorIs(t, tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateOrderAnalyticsFilter(t *testing.T) {
+	t.Parallel()
+
+	startDate := time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2023, 4, 8, 0, 0, 0, 0, time.UTC)
+	negativeRegion := -1
+
+	testcases := []struct {
+		name        string
+		in          entity.OrderAnalyticsFilter
+		expectedErr error
+	}{
+		{
+			name: "success",
+			in: entity.OrderAnalyticsFilter{
+				StartDate:   startDate,
+				EndDate:     endDate,
+				Bucket:      "week",
+				CourierType: "AUTO",
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "wrong period",
+			in: entity.OrderAnalyticsFilter{
+				StartDate: startDate,
+				EndDate:   startDate,
+				Bucket:    "day",
+			},
+			expectedErr: errors.New("the end_date must not be less or equal than the start_date"),
+		},
+		{
+			name: "wrong bucket",
+			in: entity.OrderAnalyticsFilter{
+				StartDate: startDate,
+				EndDate:   endDate,
+				Bucket:    "month",
+			},
+			expectedErr: errors.New("invalid bucket format"),
+		},
+		{
+			name: "wrong region",
+			in: entity.OrderAnalyticsFilter{
+				StartDate: startDate,
+				EndDate:   endDate,
+				Bucket:    "day",
+				Region:    &negativeRegion,
+			},
+			expectedErr: errors.New("the region can't be less than zero"),
+		},
+		{
+			name: "wrong courier type",
+			in: entity.OrderAnalyticsFilter{
+				StartDate:   startDate,
+				EndDate:     endDate,
+				Bucket:      "day",
+				CourierType: "BOAT",
+			},
+			expectedErr: errors.New("invalid courier type format"),
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := v1.ValidateOrderAnalyticsFilter(tc.in)
+
+			if tc.expectedErr != nil {
+				require.EqualError(t, err, tc.expectedErr.Error())
+			} else {
+				require.ErrorIs(t, tc.expectedErr, err)
+			}
+		})
+	}
+}
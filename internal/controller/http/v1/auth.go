@@ -0,0 +1,76 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/almostinf/order_delivery_service/internal/auth"
+	"github.com/gin-gonic/gin"
+)
+
+const _principalKey = "principal"
+
+// The authorizer checks who makes a request and whether its role may call
+// the route. A nil authenticator disables both checks.
+type authorizer struct {
+	a auth.Authenticator
+}
+
+func (z *authorizer) enabled() bool {
+	return z.a != nil
+}
+
+// The authenticate middleware resolves the principal of the request.
+func (z *authorizer) authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !z.enabled() {
+			c.Next()
+			return
+		}
+
+		principal, err := z.a.Authenticate(c.Request)
+		if err != nil {
+			requestLogger(c).Error(err, "http - v1 - auth - authenticate")
+
+			msg := "invalid credentials"
+			if errors.Is(err, auth.ErrNoCredentials) {
+				msg = "credentials are required"
+			}
+			errorResponse(c, http.StatusUnauthorized, msg)
+
+			return
+		}
+
+		c.Set(_principalKey, principal)
+		c.Next()
+	}
+}
+
+// The allow middleware lets only the given roles call the route.
+func (z *authorizer) allow(roles ...auth.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !z.enabled() {
+			c.Next()
+			return
+		}
+
+		principal := principalFromContext(c)
+		if principal == nil || !principal.HasRole(roles...) {
+			errorResponse(c, http.StatusForbidden, "the role is not allowed to call the route")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func principalFromContext(c *gin.Context) *auth.Principal {
+	value, ok := c.Get(_principalKey)
+	if !ok {
+		return nil
+	}
+
+	principal, _ := value.(*auth.Principal)
+
+	return principal
+}
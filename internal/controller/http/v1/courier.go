@@ -9,28 +9,30 @@ import (
 	"strings"
 	"time"
 
+	"github.com/almostinf/order_delivery_service/internal/auth"
 	"github.com/almostinf/order_delivery_service/internal/entity"
 	"github.com/almostinf/order_delivery_service/internal/usecase"
-	"github.com/almostinf/order_delivery_service/pkg/logger"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 type courierRoutes struct {
-	uc usecase.CourierUseCase
-	l  logger.Interface
+	uc      usecase.CourierUseCase
+	regions usecase.RegionUseCase
 }
 
-func newCourierRoutes(handler *gin.RouterGroup, uc usecase.CourierUseCase, l logger.Interface) {
-	r := &courierRoutes{uc, l}
+func newCourierRoutes(handler *gin.RouterGroup, z *authorizer, uc usecase.CourierUseCase, regions usecase.RegionUseCase) {
+	r := &courierRoutes{uc, regions}
 
 	h := handler.Group("/couriers")
 	{
-		h.GET("/", r.getAll)
-		h.GET("/:courier_id", r.get)
-		h.POST("/", r.create)
-		h.GET("/meta-info/:courier_id", r.getMetaInfo)
-		h.GET("/assignments", r.getAssignments)
+		readers := z.allow(auth.RoleAdmin, auth.RoleDispatcher, auth.RoleIntegration)
+
+		h.GET("/", readers, r.getAll)
+		h.GET("/:courier_id", readers, r.get)
+		h.POST("/", z.allow(auth.RoleAdmin, auth.RoleIntegration), r.create)
+		h.GET("/meta-info/:courier_id", z.allow(auth.RoleAdmin, auth.RoleDispatcher), r.getMetaInfo)
+		h.GET("/assignments", z.allow(auth.RoleAdmin, auth.RoleDispatcher), r.getAssignments)
 	}
 }
 
@@ -49,14 +51,18 @@ type getAllCouriersResponse struct {
 // @Param       offset query int false "Offset the list of results (default: 0)"
 // @Success     200 {object} getAllCouriersResponse
 // @Failure     400 {object} response
+// @Failure     401 {object} response
+// @Failure     403 {object} response
 // @Failure     500 {object} response
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
 // @Router      /couriers/ [get]
 func (r *courierRoutes) getAll(c *gin.Context) {
 	limit, offset := 1, 0
 	if limitStr, ok := c.GetQuery("limit"); ok {
 		parsedLimit, err := strconv.Atoi(limitStr)
 		if err != nil {
-			r.l.Error(err, "http - v1 - courier - getAll - strconv.Atoi")
+			requestLogger(c).Error(err, "http - v1 - courier - getAll - strconv.Atoi")
 			errorResponse(c, http.StatusBadRequest, "failed conversation offset to int")
 
 			return
@@ -67,7 +73,7 @@ func (r *courierRoutes) getAll(c *gin.Context) {
 	if offsetStr, ok := c.GetQuery("offset"); ok {
 		parsedOffset, err := strconv.Atoi(offsetStr)
 		if err != nil {
-			r.l.Error(err, "http - v1 - courier - getAll - strconv.Atoi")
+			requestLogger(c).Error(err, "http - v1 - courier - getAll - strconv.Atoi")
 			errorResponse(c, http.StatusBadRequest, "failed conversation offset to int")
 
 			return
@@ -76,7 +82,7 @@ func (r *courierRoutes) getAll(c *gin.Context) {
 	}
 
 	if limit < 0 || offset < 0 {
-		r.l.Error(errors.New("offset or limit is less than zero"), "http - v1 - courier - getAll")
+		requestLogger(c).Error(errors.New("offset or limit is less than zero"), "http - v1 - courier - getAll")
 		errorResponse(c, http.StatusBadRequest, "wrong limit or offset format")
 
 		return
@@ -84,8 +90,8 @@ func (r *courierRoutes) getAll(c *gin.Context) {
 
 	couriers, err := r.uc.GetAll(c.Request.Context(), limit, offset)
 	if err != nil {
-		r.l.Error(err, "http - v1 - courier - getAll - GetAll")
-		errorResponse(c, http.StatusInternalServerError, "courier service problems")
+		requestLogger(c).Error(err, "http - v1 - courier - getAll - GetAll")
+		errorResponseFromError(c, err, "courier service problems")
 
 		return
 	}
@@ -107,14 +113,19 @@ func (r *courierRoutes) getAll(c *gin.Context) {
 // @Param       courier_id path string true "Courier ID"
 // @Success     200 {object} entity.CourierResponse
 // @Failure     400 {object} response
+// @Failure     404 {object} response
+// @Failure     401 {object} response
+// @Failure     403 {object} response
 // @Failure     500 {object} response
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
 // @Router      /couriers/{courier_id} [get]
 func (r *courierRoutes) get(c *gin.Context) {
 	idStr := c.Param("courier_id")
 
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		r.l.Error(err, "http - v1 - courier - get - uuid.Parse")
+		requestLogger(c).Error(err, "http - v1 - courier - get - uuid.Parse")
 		errorResponse(c, http.StatusBadRequest, "failed conversation id (string) to uuid")
 
 		return
@@ -122,8 +133,8 @@ func (r *courierRoutes) get(c *gin.Context) {
 
 	courier, err := r.uc.Get(c.Request.Context(), id)
 	if err != nil {
-		r.l.Error(err, "http - v1 - courier - get - Get")
-		errorResponse(c, http.StatusNotFound, "courier service problems")
+		requestLogger(c).Error(err, "http - v1 - courier - get - Get")
+		errorResponseFromError(c, err, "courier service problems")
 
 		return
 	}
@@ -156,33 +167,9 @@ func parseTimeRange(s string) error {
 	return nil
 }
 
-func validateRegions(regions []int) error {
-	if len(regions) == 0 {
-		return errors.New("invalid courier regions format (regions are empty)")
-	}
-
-	for _, region := range regions {
-		if region < 0 {
-			return errors.New("invalid courier region format")
-		}
-	}
-
-	return nil
-}
-
 func ValidateCourierRequest(courier CreateCourierRequest) error {
-	if courier.CourierType != "FOOT" && courier.CourierType != "BIKE" && courier.CourierType != "AUTO" {
-		return errors.New("invalid courier type format")
-	}
-
-	if err := validateRegions(courier.Regions); err != nil {
-		return err
-	}
-
-	for _, workingTime := range courier.WorkingHours {
-		if err := parseTimeRange(workingTime); err != nil {
-			return fmt.Errorf("invalid working time: %w", err)
-		}
+	if violations := courierViolations(0, courier); len(violations) > 0 {
+		return violations[0]
 	}
 
 	return nil
@@ -203,34 +190,63 @@ type CreateCourierRequest struct {
 // @Param       request body object true "Courier object"
 // @Success     200 {object} entity.CourierResponse
 // @Failure     400 {object} response
+// @Failure     401 {object} response
+// @Failure     403 {object} response
 // @Failure     500 {object} response
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
 // @Router      /couriers/ [post]
 func (r *courierRoutes) create(c *gin.Context) {
 	couriersReq := make(map[string][]CreateCourierRequest)
 
 	if err := c.ShouldBindJSON(&couriersReq); err != nil {
-		r.l.Error(err, "http - v1 - courier - create")
-		errorResponse(c, http.StatusBadRequest, "invalid request body")
+		requestLogger(c).Error(err, "http - v1 - courier - create")
+		validationErrorResponse(c, bindViolations(err))
 
 		return
 	}
 
 	if _, ok := couriersReq["couriers"]; !ok {
-		r.l.Error(errors.New("invalid key in request body"), "http - v1 - courier - create")
-		errorResponse(c, http.StatusBadRequest, "invalid request body")
+		requestLogger(c).Error(errors.New("invalid key in request body"), "http - v1 - courier - create")
+		validationErrorResponse(c, []Violation{missingKeyViolation("couriers")})
 
 		return
 	}
 
-	for _, courierReq := range couriersReq["couriers"] {
-		if err := ValidateCourierRequest(courierReq); err != nil {
-			r.l.Error(err, "http - v1 - courier - create")
-			errorResponse(c, http.StatusBadRequest, "invalid request body")
+	violations := make([]Violation, 0)
+	for i, courierReq := range couriersReq["couriers"] {
+		violations = append(violations, courierViolations(i, courierReq)...)
+	}
 
-			return
+	if len(violations) > 0 {
+		requestLogger(c).Error(violations[0], "http - v1 - courier - create")
+		validationErrorResponse(c, violations)
+
+		return
+	}
+
+	refs := make([]regionReference, 0)
+	for i, courierReq := range couriersReq["couriers"] {
+		for j, region := range courierReq.Regions {
+			refs = append(refs, regionReference{i, fmt.Sprintf("couriers[%d].regions[%d]", i, j), region})
 		}
 	}
 
+	violations, err := unknownRegionViolations(c.Request.Context(), r.regions, refs)
+	if err != nil {
+		requestLogger(c).Error(err, "http - v1 - courier - create - unknownRegionViolations")
+		errorResponseFromError(c, err, "region service problems")
+
+		return
+	}
+
+	if len(violations) > 0 {
+		requestLogger(c).Error(violations[0], "http - v1 - courier - create")
+		validationErrorResponse(c, violations)
+
+		return
+	}
+
 	response := make(map[string][]*entity.CourierResponse)
 	response["couriers"] = make([]*entity.CourierResponse, 0)
 
@@ -250,8 +266,8 @@ func (r *courierRoutes) create(c *gin.Context) {
 		)
 
 		if err != nil {
-			r.l.Error(err, "http - v1 - courier - create")
-			errorResponse(c, http.StatusInternalServerError, "courier service problems")
+			requestLogger(c).Error(err, "http - v1 - courier - create")
+			errorResponseFromError(c, err, "courier service problems")
 
 			return
 		}
@@ -270,18 +286,23 @@ func (r *courierRoutes) create(c *gin.Context) {
 // @Tags  	    couriers
 // @Produce     json
 // @Param       courier_id path string true "Courier ID"
-// @Param       start_date query string true "Start Date"
-// @Param       end_date query string true "End Date"
+// @Param       start_date query string true "Start Date, inclusive"
+// @Param       end_date query string true "End Date, exclusive"
 // @Success     200 {object} entity.CourierMetaInfo
 // @Failure     400 {object} response
+// @Failure     404 {object} response
+// @Failure     401 {object} response
+// @Failure     403 {object} response
 // @Failure     500 {object} response
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
 // @Router      /couriers/meta-info/{courier_id} [get]
 func (r *courierRoutes) getMetaInfo(c *gin.Context) {
 	idStr := c.Param("courier_id")
 
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		r.l.Error(err, "http - v1 - courier - getMetaInfo - uuid.Parse")
+		requestLogger(c).Error(err, "http - v1 - courier - getMetaInfo - uuid.Parse")
 		errorResponse(c, http.StatusBadRequest, "failed conversation id (string) to uuid")
 
 		return
@@ -292,7 +313,7 @@ func (r *courierRoutes) getMetaInfo(c *gin.Context) {
 	layout := "2006-01-02"
 	startDate, err := time.Parse(layout, startDateStr)
 	if err != nil {
-		r.l.Error(err, "http - v1 - courier - getMetaInfo - time.Parse(start_date)")
+		requestLogger(c).Error(err, "http - v1 - courier - getMetaInfo - time.Parse(start_date)")
 		errorResponse(c, http.StatusBadRequest, "failed conversation start_date to time")
 
 		return
@@ -300,14 +321,14 @@ func (r *courierRoutes) getMetaInfo(c *gin.Context) {
 
 	endDate, err := time.Parse(layout, endDateStr)
 	if err != nil {
-		r.l.Error(err, "http - v1 - courier - getMetaInfo - time.Parse(end_date)")
+		requestLogger(c).Error(err, "http - v1 - courier - getMetaInfo - time.Parse(end_date)")
 		errorResponse(c, http.StatusBadRequest, "failed conversation end_date to time")
 
 		return
 	}
 
 	if endDate.Sub(startDate) <= 0 {
-		r.l.Error(err, "http - v1 - courier - getMetaInfo")
+		requestLogger(c).Error(err, "http - v1 - courier - getMetaInfo")
 		errorResponse(c, http.StatusBadRequest, "the end_date must not be less or equal than the start_date")
 
 		return
@@ -315,8 +336,8 @@ func (r *courierRoutes) getMetaInfo(c *gin.Context) {
 
 	courierMetaInfo, err := r.uc.GetMetaInfo(c.Request.Context(), id, startDate, endDate)
 	if err != nil {
-		r.l.Error(err, "http - v1 - courier - getMetaInfo")
-		errorResponse(c, http.StatusInternalServerError, "courier service problems")
+		requestLogger(c).Error(err, "http - v1 - courier - getMetaInfo")
+		errorResponseFromError(c, err, "courier service problems")
 
 		return
 	}
@@ -333,10 +354,14 @@ func (r *courierRoutes) getMetaInfo(c *gin.Context) {
 // @Param       courier_id query string false "Courier ID"
 // @Success     200 {object} couriersAssignResponse
 // @Failure     400 {object} response
+// @Failure     401 {object} response
+// @Failure     403 {object} response
 // @Failure     500 {object} response
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
 // @Router      /couriers/assignments [get]
 func (r *courierRoutes) getAssignments(c *gin.Context) {
-	date := time.Now().Truncate(24 * time.Hour)
+	date := entity.Today()
 	isAllCouriers := true
 	var courierID uuid.UUID
 
@@ -344,7 +369,7 @@ func (r *courierRoutes) getAssignments(c *gin.Context) {
 		layout := "2006-01-02"
 		parsedDate, err := time.Parse(layout, dateStr)
 		if err != nil {
-			r.l.Error(err, "http - v1 - courier - getAssignments - time.Parse(date)")
+			requestLogger(c).Error(err, "http - v1 - courier - getAssignments - time.Parse(date)")
 			errorResponse(c, http.StatusBadRequest, "failed conversation date to time")
 
 			return
@@ -355,7 +380,7 @@ func (r *courierRoutes) getAssignments(c *gin.Context) {
 	if courierIDStr, ok := c.GetQuery("courier_id"); ok {
 		parsedCourierID, err := uuid.Parse(courierIDStr)
 		if err != nil {
-			r.l.Error(err, "http - v1 - courier - getAssignments - uuid.Parse(courier_id)")
+			requestLogger(c).Error(err, "http - v1 - courier - getAssignments - uuid.Parse(courier_id)")
 			errorResponse(c, http.StatusBadRequest, "failed conversation courier_id to uuid")
 
 			return
@@ -366,8 +391,8 @@ func (r *courierRoutes) getAssignments(c *gin.Context) {
 
 	courierAssignments, err := r.uc.GetAssignments(c.Request.Context(), date, courierID, isAllCouriers)
 	if err != nil {
-		r.l.Error(err, "http - v1 - courier - getAssignments")
-		errorResponse(c, http.StatusInternalServerError, "courier service problem")
+		requestLogger(c).Error(err, "http - v1 - courier - getAssignments")
+		errorResponseFromError(c, err, "courier service problem")
 
 		return
 	}
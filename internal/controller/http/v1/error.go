@@ -1,18 +1,94 @@
 package v1
 
 import (
+	"errors"
+	"net/http"
+
+	"github.com/almostinf/order_delivery_service/internal/entity"
 	"github.com/gin-gonic/gin"
 )
 
+// Error codes returned in the code field of error responses, so clients
+// don't have to parse the message.
+const (
+	_codeBadRequest          = "bad_request"
+	_codeUnauthorized        = "unauthorized"
+	_codeForbidden           = "forbidden"
+	_codeValidation          = "validation_error"
+	_codeNotFound            = "not_found"
+	_codeConflict            = "conflict"
+	_codeForbiddenTransition = "forbidden_transition"
+	_codeTooManyRequests     = "too_many_requests"
+	_codeInternal            = "internal_error"
+)
+
 // The response struct represents the format of error responses that will be
 // sent by the API.
 type response struct {
-	Error string `json:"error" example:"message"`
+	Error      string      `json:"error" example:"message"`
+	Code       string      `json:"code" example:"bad_request"`
+	Violations []Violation `json:"violations,omitempty"`
+	Details    []string    `json:"details,omitempty"`
 }
 
 // The errorResponse function is a utility function that generates an error
 // response with the given msg and HTTP code, and aborts the current
 // request with that response.
 func errorResponse(c *gin.Context, code int, msg string) {
-	c.AbortWithStatusJSON(code, response{msg})
+	c.AbortWithStatusJSON(code, response{Error: msg, Code: statusErrorCode(code)})
+}
+
+// The errorResponseFromError function picks the HTTP code for an error
+// returned by a use case. Messages of domain errors are shown to the client,
+// any other error is reported with the given msg.
+func errorResponseFromError(c *gin.Context, err error, msg string) {
+	status, code := MapError(err)
+
+	var details []string
+
+	var domainErr *entity.DomainError
+	if status != http.StatusInternalServerError && errors.As(err, &domainErr) {
+		msg = domainErr.Message
+		details = domainErr.Details
+	}
+
+	c.AbortWithStatusJSON(status, response{Error: msg, Code: code, Details: details})
+}
+
+// MapError returns the HTTP status and the error code for an error
+// returned by a use case.
+func MapError(err error) (int, string) {
+	switch {
+	case errors.Is(err, entity.ErrValidation):
+		return http.StatusBadRequest, _codeValidation
+	case errors.Is(err, entity.ErrNotFound):
+		return http.StatusNotFound, _codeNotFound
+	case errors.Is(err, entity.ErrConflict):
+		return http.StatusConflict, _codeConflict
+	case errors.Is(err, entity.ErrForbiddenTransition):
+		return http.StatusUnprocessableEntity, _codeForbiddenTransition
+	default:
+		return http.StatusInternalServerError, _codeInternal
+	}
+}
+
+func statusErrorCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return _codeBadRequest
+	case http.StatusUnauthorized:
+		return _codeUnauthorized
+	case http.StatusForbidden:
+		return _codeForbidden
+	case http.StatusNotFound:
+		return _codeNotFound
+	case http.StatusConflict:
+		return _codeConflict
+	case http.StatusUnprocessableEntity:
+		return _codeForbiddenTransition
+	case http.StatusTooManyRequests:
+		return _codeTooManyRequests
+	default:
+		return _codeInternal
+	}
 }
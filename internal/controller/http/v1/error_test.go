@@ -0,0 +1,67 @@
+package v1_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	v1 "github.com/almostinf/order_delivery_service/internal/controller/http/v1"
+	"github.com/almostinf/order_delivery_service/internal/entity"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapError(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name           string
+		in             error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:           "validation",
+			in:             fmt.Errorf("OrderRepo - Complete - %w", entity.NewDomainError(entity.ErrValidation, "complete_time is in the future")),
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "validation_error",
+		},
+		{
+			name:           "not found",
+			in:             fmt.Errorf("CourierUseCase - Get - uc.repo.Get: %w", entity.NewDomainError(entity.ErrNotFound, "courier not found")),
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "not_found",
+		},
+		{
+			name:           "conflict",
+			in:             fmt.Errorf("OrderRepo - SetCourierID: %w", entity.ErrConflict),
+			expectedStatus: http.StatusConflict,
+			expectedCode:   "conflict",
+		},
+		{
+			name:           "forbidden transition",
+			in:             entity.NewDomainError(entity.ErrForbiddenTransition, "courier_id and the order courier don't match"),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "forbidden_transition",
+		},
+		{
+			name:           "internal",
+			in:             errors.New("connection refused"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "internal_error",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			status, code := v1.MapError(tc.in)
+
+			require.Equal(t, tc.expectedStatus, status)
+			require.Equal(t, tc.expectedCode, code)
+		})
+	}
+}
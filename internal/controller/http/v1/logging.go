@@ -0,0 +1,94 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/almostinf/order_delivery_service/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	_requestIDHeader    = "X-Request-ID"
+	_maxRequestIDLength = 128
+)
+
+// The NewRequestLoggerMiddleware function takes the X-Request-ID of the
+// request or generates one, returns it in the response and stores a logger
+// with the request_id (and trace_id when tracing is on) fields in the request
+// context, so the use cases and repositories log with it. Every request is
+// logged once it's served.
+func NewRequestLoggerMiddleware(l logger.Interface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestID := c.GetHeader(_requestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+		c.Header(_requestIDHeader, requestID)
+
+		reqLogger := l.With("request_id", requestID)
+		if spanContext := trace.SpanContextFromContext(c.Request.Context()); spanContext.IsValid() {
+			reqLogger = reqLogger.With("trace_id", spanContext.TraceID().String())
+		}
+		c.Request = c.Request.WithContext(logger.WithContext(c.Request.Context(), reqLogger))
+
+		c.Next()
+
+		path := c.FullPath()
+		if path == "" {
+			path = c.Request.URL.Path
+		}
+
+		entry := reqLogger.
+			With("method", c.Request.Method).
+			With("path", path).
+			With("status", c.Writer.Status()).
+			With("latency_ms", time.Since(start).Milliseconds()).
+			With("client_ip", c.ClientIP()).
+			With("size", c.Writer.Size())
+
+		if principal := principalFromContext(c); principal != nil {
+			entry = entry.With("principal", principal.Subject)
+		}
+
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			entry.Error("request failed")
+		} else {
+			entry.Info("request served")
+		}
+	}
+}
+
+// The NewRecoveryMiddleware function logs the panics of the handlers with
+// the request logger and responds with the internal error.
+func NewRecoveryMiddleware() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
+		requestLogger(c).Error(fmt.Sprintf("panic recovered: %v", recovered))
+		errorResponse(c, http.StatusInternalServerError, "internal server error")
+	})
+}
+
+// requestLogger returns the logger of the request set by the request logger
+// middleware.
+func requestLogger(c *gin.Context) logger.Interface {
+	return logger.FromContext(c.Request.Context())
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > _maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range id {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
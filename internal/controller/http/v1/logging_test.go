@@ -0,0 +1,74 @@
+package v1_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	v1 "github.com/almostinf/order_delivery_service/internal/controller/http/v1"
+	"github.com/almostinf/order_delivery_service/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestLoggerMiddleware(t *testing.T) {
+	t.Parallel()
+
+	handler := gin.New()
+	handler.Use(v1.NewRequestLoggerMiddleware(logger.New("error")))
+	handler.GET("/", func(c *gin.Context) {
+		_, isLogger := logger.FromContext(c.Request.Context()).(*logger.Logger)
+		require.True(t, isLogger)
+		c.Status(http.StatusOK)
+	})
+
+	testcases := []struct {
+		name     string
+		in       string
+		expected func(t *testing.T, requestID string)
+	}{
+		{
+			name: "propagated",
+			in:   "req-1",
+			expected: func(t *testing.T, requestID string) {
+				require.Equal(t, "req-1", requestID)
+			},
+		},
+		{
+			name: "generated",
+			expected: func(t *testing.T, requestID string) {
+				_, err := uuid.Parse(requestID)
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "too long is replaced",
+			in:   strings.Repeat("a", 129),
+			expected: func(t *testing.T, requestID string) {
+				_, err := uuid.Parse(requestID)
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.in != "" {
+				req.Header.Set("X-Request-ID", tc.in)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			tc.expected(t, w.Header().Get("X-Request-ID"))
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/almostinf/order_delivery_service/internal/auth"
+	"github.com/almostinf/order_delivery_service/internal/entity"
+	"github.com/almostinf/order_delivery_service/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	_courierIDHeader = "X-Courier-ID"
+	_courierIDKey    = "courier_id"
+)
+
+type meRoutes struct {
+	c usecase.CourierUseCase
+	o usecase.OrderUseCase
+}
+
+func newMeRoutes(handler *gin.RouterGroup, z *authorizer, c usecase.CourierUseCase, o usecase.OrderUseCase) {
+	r := &meRoutes{c, o}
+
+	h := handler.Group("/me", z.allow(auth.RoleCourier), courierIdentity(z))
+	{
+		h.GET("/orders", r.getOrders)
+		h.POST("/groups/:group_order_id/start", r.startGroup)
+		h.POST("/orders/:order_id/complete", r.completeOrder)
+	}
+}
+
+// The courierIdentity middleware stores the id of the courier the request is
+// made by in the context. It comes from the token, the X-Courier-ID header
+// is only trusted when authentication is disabled.
+func courierIdentity(z *authorizer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if z.enabled() {
+			c.Set(_courierIDKey, principalFromContext(c).CourierID)
+			c.Next()
+
+			return
+		}
+
+		courierID, err := uuid.Parse(c.GetHeader(_courierIDHeader))
+		if err != nil {
+			requestLogger(c).Error(err, "http - v1 - me - courierIdentity - uuid.Parse")
+			errorResponse(c, http.StatusUnauthorized, "courier is not authenticated")
+
+			return
+		}
+
+		c.Set(_courierIDKey, courierID)
+		c.Next()
+	}
+}
+
+func courierIDFromContext(c *gin.Context) uuid.UUID {
+	courierID, _ := c.Get(_courierIDKey)
+	id, _ := courierID.(uuid.UUID)
+
+	return id
+}
+
+type deliveryPlanResponse struct {
+	Date      time.Time            `json:"date" example:"2023-04-01T00:00:00Z"`
+	CourierID uuid.UUID            `json:"courier_id"`
+	Groups    []entity.OrdersGroup `json:"groups"`
+}
+
+type completeMyOrderRequest struct {
+	CompleteTime time.Time `json:"complete_time" binding:"required"`
+}
+
+func (r *meRoutes) todayPlan(c *gin.Context) (time.Time, *entity.CourierAssignment, bool) {
+	date := entity.Today()
+
+	plan, err := r.c.GetDeliveryPlan(c.Request.Context(), courierIDFromContext(c), date)
+	if err != nil {
+		requestLogger(c).Error(err, "http - v1 - me - todayPlan")
+		errorResponseFromError(c, err, "courier service problem")
+
+		return date, nil, false
+	}
+
+	return date, plan, true
+}
+
+// @Summary     Get My Orders
+// @Description Get today's groups of the courier in delivery order
+// @ID          get-my-orders
+// @Tags  	    me
+// @Produce     json
+// @Param       X-Courier-ID header string false "Courier ID, when authentication is disabled"
+// @Success     200 {object} deliveryPlanResponse
+// @Failure     401 {object} response
+// @Failure     403 {object} response
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Security    BearerAuth
+// @Router      /me/orders [get]
+func (r *meRoutes) getOrders(c *gin.Context) {
+	date, plan, ok := r.todayPlan(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveryPlanResponse{
+		Date:      date,
+		CourierID: plan.CourierID,
+		Groups:    plan.Orders,
+	})
+}
+
+// @Summary     Start Group
+// @Description Mark today's group of the courier as taken on the trip
+// @ID          start-my-group
+// @Tags  	    me
+// @Produce     json
+// @Param       X-Courier-ID header string false "Courier ID, when authentication is disabled"
+// @Param       group_order_id path string true "Group Order ID"
+// @Success     200 {object} entity.OrdersGroup
+// @Failure     400 {object} response
+// @Failure     401 {object} response
+// @Failure     403 {object} response
+// @Failure     404 {object} response
+// @Failure     422 {object} response
+// @Failure     500 {object} response
+// @Security    BearerAuth
+// @Router      /me/groups/{group_order_id}/start [post]
+func (r *meRoutes) startGroup(c *gin.Context) {
+	groupOrderID, err := uuid.Parse(c.Param("group_order_id"))
+	if err != nil {
+		requestLogger(c).Error(err, "http - v1 - me - startGroup - uuid.Parse(group_order_id)")
+		errorResponse(c, http.StatusBadRequest, "failed conversation group_order_id to uuid")
+
+		return
+	}
+
+	_, plan, ok := r.todayPlan(c)
+	if !ok {
+		return
+	}
+
+	var group *entity.OrdersGroup
+	for i := range plan.Orders {
+		if plan.Orders[i].GroupOrderID == groupOrderID {
+			group = &plan.Orders[i]
+		}
+	}
+
+	if group == nil {
+		errorResponse(c, http.StatusNotFound, "group not found in today's plan")
+
+		return
+	}
+
+	orderIDs := make([]uuid.UUID, 0, len(group.Orders))
+	for _, order := range group.Orders {
+		orderIDs = append(orderIDs, order.OrderID)
+	}
+
+	startTime := time.Now()
+
+	if _, err := r.o.StartDelivery(c.Request.Context(), plan.CourierID, orderIDs, startTime); err != nil {
+		requestLogger(c).Error(err, "http - v1 - me - startGroup")
+		errorResponseFromError(c, err, "order service problem")
+
+		return
+	}
+
+	if group.StartedTime.IsZero() {
+		group.StartedTime = startTime
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+// @Summary     Complete My Order
+// @Description Confirm delivery of an order from today's plan of the courier
+// @ID          complete-my-order
+// @Tags  	    me
+// @Accept      json
+// @Produce     json
+// @Param       X-Courier-ID header string false "Courier ID, when authentication is disabled"
+// @Param       order_id path string true "Order ID"
+// @Param       request body completeMyOrderRequest true "Completion time"
+// @Success     200 {object} entity.OrderResponse
+// @Failure     400 {object} response
+// @Failure     401 {object} response
+// @Failure     403 {object} response
+// @Failure     404 {object} response
+// @Failure     422 {object} response
+// @Failure     500 {object} response
+// @Security    BearerAuth
+// @Router      /me/orders/{order_id}/complete [post]
+func (r *meRoutes) completeOrder(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("order_id"))
+	if err != nil {
+		requestLogger(c).Error(err, "http - v1 - me - completeOrder - uuid.Parse(order_id)")
+		errorResponse(c, http.StatusBadRequest, "failed conversation order_id to uuid")
+
+		return
+	}
+
+	var req completeMyOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		requestLogger(c).Error(err, "http - v1 - me - completeOrder")
+		validationErrorResponse(c, bindViolations(err))
+
+		return
+	}
+
+	_, plan, ok := r.todayPlan(c)
+	if !ok {
+		return
+	}
+
+	if !planHasOrder(plan, orderID) {
+		errorResponse(c, http.StatusNotFound, "order not found in today's plan")
+
+		return
+	}
+
+	orders, err := r.o.Complete(c.Request.Context(), []entity.CompleteInfo{{
+		CourierID:    plan.CourierID,
+		OrderID:      orderID,
+		CompleteTime: req.CompleteTime,
+	}})
+	if err != nil {
+		requestLogger(c).Error(err, "http - v1 - me - completeOrder")
+		errorResponseFromError(c, err, "order service problem")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, orders[0])
+}
+
+func planHasOrder(plan *entity.CourierAssignment, orderID uuid.UUID) bool {
+	for _, group := range plan.Orders {
+		for _, order := range group.Orders {
+			if order.OrderID == orderID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,53 @@
+package v1_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/almostinf/order_delivery_service/internal/auth"
+	v1 "github.com/almostinf/order_delivery_service/internal/controller/http/v1"
+	"github.com/almostinf/order_delivery_service/internal/entity"
+	mocks "github.com/almostinf/order_delivery_service/internal/mocks/repo"
+	"github.com/almostinf/order_delivery_service/internal/usecase"
+	"github.com/almostinf/order_delivery_service/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// The test changes time.Local, so it doesn't run in parallel with the others.
+func TestMyOrdersUseUTCDate(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("UTC+3", 3*60*60)
+	t.Cleanup(func() { time.Local = local })
+
+	gin.SetMode(gin.TestMode)
+
+	courierID := uuid.New()
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	mockCtrl := gomock.NewController(t)
+
+	// The plans are stored under the UTC midnight, see SetCourierID
+	courierRepo := mocks.NewMockCourier(mockCtrl)
+	courierRepo.EXPECT().GetAssignments(gomock.Any(), today, courierID, false).Return([]*entity.CourierAssignment{}, nil).Times(1)
+
+	jwt := auth.NewJWTAuthenticator([]byte("secret"))
+	token, err := jwt.Sign(auth.Claims{Subject: "courier", Role: auth.RoleCourier, CourierID: courierID, ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	require.NoError(t, err)
+
+	handler := gin.New()
+	v1.NewRouter(handler, logger.New("error"), jwt, nil, nil, nil,
+		*usecase.NewCourierUseCase(courierRepo), *usecase.NewOrderUseCase(mocks.NewMockOrder(mockCtrl)),
+		*usecase.NewAnalyticsUseCase(mocks.NewMockAnalytics(mockCtrl)), *usecase.NewRegionUseCase(mocks.NewMockRegion(mockCtrl)))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/me/orders", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
@@ -0,0 +1,30 @@
+package v1
+
+import (
+	"time"
+
+	"github.com/almostinf/order_delivery_service/internal/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// The NewMetricsMiddleware function counts the requests by route template,
+// so the paths with ids don't make a series per id.
+func NewMetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		metrics.ObserveHTTPRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
+
+func newMetricsRoutes(handler *gin.Engine) {
+	handler.GET("/metrics", gin.WrapH(promhttp.Handler()))
+}
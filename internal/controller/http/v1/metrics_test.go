@@ -0,0 +1,35 @@
+package v1
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := gin.New()
+	handler.Use(NewMetricsMiddleware())
+	handler.GET("/v1/orders/:order_id", func(c *gin.Context) {
+		c.Status(http.StatusNotFound)
+	})
+	newMetricsRoutes(handler)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/orders/42", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	body, err := io.ReadAll(w.Body)
+	require.NoError(t, err)
+	require.Contains(t, string(body), `order_delivery_http_requests_total{method="GET",route="/v1/orders/:order_id",status="404"} 1`)
+	require.NotContains(t, string(body), `/v1/orders/42`)
+}
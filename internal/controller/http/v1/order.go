@@ -7,29 +7,34 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/almostinf/order_delivery_service/internal/auth"
 	"github.com/almostinf/order_delivery_service/internal/entity"
 	"github.com/almostinf/order_delivery_service/internal/usecase"
-	"github.com/almostinf/order_delivery_service/pkg/logger"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 type orderRoutes struct {
-	uc usecase.OrderUseCase
-	l  logger.Interface
+	uc      usecase.OrderUseCase
+	regions usecase.RegionUseCase
 }
 
-func newOrderRoutes(handler *gin.RouterGroup, uc usecase.OrderUseCase, l logger.Interface) {
-	r := &orderRoutes{uc, l}
+func newOrderRoutes(handler *gin.RouterGroup, z *authorizer, uc usecase.OrderUseCase, regions usecase.RegionUseCase) {
+	r := &orderRoutes{uc, regions}
 
 	h := handler.Group("/orders")
 	{
-		h.GET("/", r.getAll)
-		h.GET("/:order_id", r.get)
-		h.POST("/", r.create)
-		h.POST("/complete", r.complete)
-		h.PUT("/set_courier", r.setCourierID)
-		h.POST("/assign", r.assign)
+		integrations := z.allow(auth.RoleAdmin, auth.RoleDispatcher, auth.RoleIntegration)
+		dispatchers := z.allow(auth.RoleAdmin, auth.RoleDispatcher)
+
+		h.GET("/", integrations, r.getAll)
+		h.GET("/:order_id", integrations, r.get)
+		h.POST("/", integrations, r.create)
+		h.POST("/complete", integrations, r.complete)
+		h.PUT("/set_courier", dispatchers, r.setCourierID)
+		h.PUT("/unassign", dispatchers, r.unassign)
+		h.PUT("/reassign", dispatchers, r.reassign)
+		h.POST("/assign", dispatchers, r.assign)
 	}
 }
 
@@ -42,14 +47,18 @@ func newOrderRoutes(handler *gin.RouterGroup, uc usecase.OrderUseCase, l logger.
 // @Param       offset query int false "Offset the list of results (default: 0)"
 // @Success     200 {array} entity.OrderResponse
 // @Failure     400 {object} response
+// @Failure     401 {object} response
+// @Failure     403 {object} response
 // @Failure     500 {object} response
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
 // @Router      /orders/ [get]
 func (r *orderRoutes) getAll(c *gin.Context) {
 	limit, offset := 1, 0
 	if limitStr, ok := c.GetQuery("limit"); ok {
 		parsedLimit, err := strconv.Atoi(limitStr)
 		if err != nil {
-			r.l.Error(err, "http - v1 - order - getAll - strconv.Atoi")
+			requestLogger(c).Error(err, "http - v1 - order - getAll - strconv.Atoi")
 			errorResponse(c, http.StatusBadRequest, "failed conversation offset to int")
 
 			return
@@ -60,7 +69,7 @@ func (r *orderRoutes) getAll(c *gin.Context) {
 	if offsetStr, ok := c.GetQuery("offset"); ok {
 		parsedOffset, err := strconv.Atoi(offsetStr)
 		if err != nil {
-			r.l.Error(err, "http - v1 - order - getAll - strconv.Atoi")
+			requestLogger(c).Error(err, "http - v1 - order - getAll - strconv.Atoi")
 			errorResponse(c, http.StatusBadRequest, "failed conversation offset to int")
 
 			return
@@ -69,7 +78,7 @@ func (r *orderRoutes) getAll(c *gin.Context) {
 	}
 
 	if limit < 0 || offset < 0 {
-		r.l.Error(errors.New("offset or limit is less than zero"), "http - v1 - order - getAll")
+		requestLogger(c).Error(errors.New("offset or limit is less than zero"), "http - v1 - order - getAll")
 		errorResponse(c, http.StatusBadRequest, "wrong limit or offset format")
 
 		return
@@ -77,8 +86,8 @@ func (r *orderRoutes) getAll(c *gin.Context) {
 
 	orders, err := r.uc.GetAll(c.Request.Context(), limit, offset)
 	if err != nil {
-		r.l.Error(err, "http - v1 - order - getAll - GetAll")
-		errorResponse(c, http.StatusInternalServerError, "order service problems")
+		requestLogger(c).Error(err, "http - v1 - order - getAll - GetAll")
+		errorResponseFromError(c, err, "order service problems")
 
 		return
 	}
@@ -94,14 +103,19 @@ func (r *orderRoutes) getAll(c *gin.Context) {
 // @Param       order_id path string true "Courier ID"
 // @Success     200 {object} entity.OrderResponse
 // @Failure     400 {object} response
+// @Failure     404 {object} response
+// @Failure     401 {object} response
+// @Failure     403 {object} response
 // @Failure     500 {object} response
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
 // @Router      /orders/{order_id} [get]
 func (r *orderRoutes) get(c *gin.Context) {
 	idStr := c.Param("order_id")
 
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		r.l.Error(err, "http - v1 - order - get - uuid.Parse")
+		requestLogger(c).Error(err, "http - v1 - order - get - uuid.Parse")
 		errorResponse(c, http.StatusBadRequest, "failed conversation id (string) to uuid")
 
 		return
@@ -109,8 +123,8 @@ func (r *orderRoutes) get(c *gin.Context) {
 
 	order, err := r.uc.Get(c.Request.Context(), id)
 	if err != nil {
-		r.l.Error(err, "http - v1 - order - get - Get")
-		errorResponse(c, http.StatusNotFound, "order service problems")
+		requestLogger(c).Error(err, "http - v1 - order - get - Get")
+		errorResponseFromError(c, err, "order service problems")
 
 		return
 	}
@@ -119,32 +133,35 @@ func (r *orderRoutes) get(c *gin.Context) {
 }
 
 func ValidateOrderRequest(order CreateOrderRequest) error {
-	if order.Weight <= 0 {
-		return errors.New("the order weight can't be less than zero")
-	}
-
-	if order.Regions < 0 {
-		return errors.New("the order region can't be less than zero")
-	}
-
-	for _, deliveryHours := range order.DeliveryHours {
-		if err := parseTimeRange(deliveryHours); err != nil {
-			return fmt.Errorf("invalid delivery hours: %w", err)
-		}
-	}
-
-	if order.Cost < 0 {
-		return errors.New("the order cost can't be less than zero")
+	if violations := orderViolations(0, order); len(violations) > 0 {
+		return violations[0]
 	}
 
 	return nil
 }
 
+// CreateOrderRequest takes either the region of the order or the delivery
+// address to resolve the region from.
 type CreateOrderRequest struct {
-	Weight        float32  `json:"weight" binding:"required"`
-	Regions       int      `json:"regions" binding:"required"`
-	DeliveryHours []string `json:"delivery_hours" binding:"required"`
-	Cost          int      `json:"cost" binding:"required"`
+	Weight        float32         `json:"weight" binding:"required"`
+	Regions       int             `json:"regions" binding:"required_without=Address"`
+	DeliveryHours []string        `json:"delivery_hours" binding:"required"`
+	Cost          int             `json:"cost" binding:"required"`
+	Address       *AddressRequest `json:"address,omitempty"`
+}
+
+type AddressRequest struct {
+	Address   string   `json:"address" binding:"required" example:"Lva Tolstogo St, 16"`
+	Latitude  *float64 `json:"latitude" binding:"required" example:"55.7339"`
+	Longitude *float64 `json:"longitude" binding:"required" example:"37.5871"`
+}
+
+func (a *AddressRequest) toEntity() *entity.Address {
+	if a == nil {
+		return nil
+	}
+
+	return &entity.Address{Address: a.Address, Latitude: *a.Latitude, Longitude: *a.Longitude}
 }
 
 // @Summary     Create Order
@@ -156,32 +173,74 @@ type CreateOrderRequest struct {
 // @Param       request body object true "Order object"
 // @Success     200 {object} entity.OrderResponse
 // @Failure     400 {object} response
+// @Failure     401 {object} response
+// @Failure     403 {object} response
 // @Failure     500 {object} response
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
 // @Router      /orders/ [post]
 func (r *orderRoutes) create(c *gin.Context) {
 	ordersReq := make(map[string][]CreateOrderRequest)
 
 	if err := c.ShouldBindJSON(&ordersReq); err != nil {
-		r.l.Error(err, "http - v1 - order - create")
-		errorResponse(c, http.StatusBadRequest, "invalid request body")
+		requestLogger(c).Error(err, "http - v1 - order - create")
+		validationErrorResponse(c, bindViolations(err))
 
 		return
 	}
 
 	if _, ok := ordersReq["orders"]; !ok {
-		r.l.Error(errors.New("invalid key in request body"), "http - v1 - order - create")
-		errorResponse(c, http.StatusBadRequest, "invalid request body")
+		requestLogger(c).Error(errors.New("invalid key in request body"), "http - v1 - order - create")
+		validationErrorResponse(c, []Violation{missingKeyViolation("orders")})
 
 		return
 	}
 
-	for _, orderReq := range ordersReq["orders"] {
-		if err := ValidateOrderRequest(orderReq); err != nil {
-			r.l.Error(err, "http - v1 - order - create")
-			errorResponse(c, http.StatusBadRequest, "invalid request body")
+	violations := make([]Violation, 0)
+	for i, orderReq := range ordersReq["orders"] {
+		violations = append(violations, orderViolations(i, orderReq)...)
+	}
 
-			return
-		}
+	if len(violations) > 0 {
+		requestLogger(c).Error(violations[0], "http - v1 - order - create")
+		validationErrorResponse(c, violations)
+
+		return
+	}
+
+	violations, err := r.resolveRegions(c, ordersReq["orders"])
+	if err != nil {
+		requestLogger(c).Error(err, "http - v1 - order - create - resolveRegions")
+		errorResponseFromError(c, err, "region service problems")
+
+		return
+	}
+
+	if len(violations) > 0 {
+		requestLogger(c).Error(violations[0], "http - v1 - order - create")
+		validationErrorResponse(c, violations)
+
+		return
+	}
+
+	refs := make([]regionReference, 0, len(ordersReq["orders"]))
+	for i, orderReq := range ordersReq["orders"] {
+		refs = append(refs, regionReference{i, fmt.Sprintf("orders[%d].regions", i), orderReq.Regions})
+	}
+
+	violations, err = unknownRegionViolations(c.Request.Context(), r.regions, refs)
+	if err != nil {
+		requestLogger(c).Error(err, "http - v1 - order - create - unknownRegionViolations")
+		errorResponseFromError(c, err, "region service problems")
+
+		return
+	}
+
+	if len(violations) > 0 {
+		requestLogger(c).Error(violations[0], "http - v1 - order - create")
+		validationErrorResponse(c, violations)
+
+		return
 	}
 
 	response := make(map[string][]*entity.OrderResponse)
@@ -198,6 +257,7 @@ func (r *orderRoutes) create(c *gin.Context) {
 					DeliveryHours: orderReq.DeliveryHours,
 					Cost:          orderReq.Cost,
 					CompletedTime: time.Time{},
+					Address:       orderReq.Address.toEntity(),
 				},
 				CreatedAt: time.Now(),
 				UpdatedAt: time.Now(),
@@ -205,8 +265,8 @@ func (r *orderRoutes) create(c *gin.Context) {
 		)
 
 		if err != nil {
-			r.l.Error(err, "http - v1 - order - create")
-			errorResponse(c, http.StatusInternalServerError, "order service problems")
+			requestLogger(c).Error(err, "http - v1 - order - create")
+			errorResponseFromError(c, err, "order service problems")
 
 			return
 		}
@@ -219,6 +279,40 @@ func (r *orderRoutes) create(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// The resolveRegions method sets the region of every order with an address
+// to the region containing it. The orders outside of the service area, or
+// with a region that doesn't match the address, are reported as violations.
+func (r *orderRoutes) resolveRegions(c *gin.Context, orders []CreateOrderRequest) ([]Violation, error) {
+	var violations []Violation
+
+	for i := range orders {
+		address := orders[i].Address
+		if address == nil {
+			continue
+		}
+
+		region, err := r.regions.Resolve(c.Request.Context(), *address.Latitude, *address.Longitude)
+		if errors.Is(err, entity.ErrValidation) {
+			violations = append(violations, Violation{i, fmt.Sprintf("orders[%d].address", i), _violationOutOfRange, "the address is outside of the service area"})
+
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if orders[i].Regions != 0 && orders[i].Regions != region {
+			violations = append(violations, Violation{i, fmt.Sprintf("orders[%d].regions", i), _violationInvalidValue, fmt.Sprintf("the order region %d doesn't match the address region %d", orders[i].Regions, region)})
+
+			continue
+		}
+
+		orders[i].Regions = region
+	}
+
+	return violations, nil
+}
+
 // @Summary     Complete Order
 // @Description Complete Order
 // @ID          complete-order
@@ -228,31 +322,49 @@ func (r *orderRoutes) create(c *gin.Context) {
 // @Param       request body object true "Complete Info Order object"
 // @Success     200 {array} entity.OrderResponse
 // @Failure     400 {object} response
+// @Failure     404 {object} response
+// @Failure     422 {object} response
+// @Failure     401 {object} response
+// @Failure     403 {object} response
 // @Failure     500 {object} response
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
 // @Router      /orders/complete [post]
 func (r *orderRoutes) complete(c *gin.Context) {
 	completeInfoReq := make(map[string][]entity.CompleteInfo)
 
 	if err := c.ShouldBindJSON(&completeInfoReq); err != nil {
-		r.l.Error(err, "http - v1 - order - complete")
-		errorResponse(c, http.StatusBadRequest, "invalid request body")
+		requestLogger(c).Error(err, "http - v1 - order - complete")
+		validationErrorResponse(c, bindViolations(err))
 
 		return
 	}
 
 	if _, ok := completeInfoReq["complete_info"]; !ok {
-		r.l.Error(errors.New("invalid key in request body"), "http - v1 - order - complete")
-		errorResponse(c, http.StatusBadRequest, "invalid request body")
+		requestLogger(c).Error(errors.New("invalid key in request body"), "http - v1 - order - complete")
+		validationErrorResponse(c, []Violation{missingKeyViolation("complete_info")})
 
 		return
 	}
 
-	r.l.Debug(completeInfoReq["complete_info"])
+	violations := make([]Violation, 0)
+	for i, completeInfo := range completeInfoReq["complete_info"] {
+		violations = append(violations, completeInfoViolations(i, completeInfo)...)
+	}
+
+	if len(violations) > 0 {
+		requestLogger(c).Error(violations[0], "http - v1 - order - complete")
+		validationErrorResponse(c, violations)
+
+		return
+	}
+
+	requestLogger(c).Debug(completeInfoReq["complete_info"])
 
 	orders, err := r.uc.Complete(c.Request.Context(), completeInfoReq["complete_info"])
 	if err != nil {
-		r.l.Error(err, "http - v1 - order - complete")
-		errorResponse(c, http.StatusBadRequest, "order service problem")
+		requestLogger(c).Error(err, "http - v1 - order - complete")
+		errorResponseFromError(c, err, "order service problem")
 
 		return
 	}
@@ -269,14 +381,20 @@ func (r *orderRoutes) complete(c *gin.Context) {
 // @Param       courier_id query string true "Courier ID"
 // @Success     200 {object} entity.OrderResponse
 // @Failure     400 {object} response
+// @Failure     404 {object} response
+// @Failure     409 {object} response
+// @Failure     401 {object} response
+// @Failure     403 {object} response
 // @Failure     500 {object} response
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
 // @Router      /orders/set_courier [put]
 func (r *orderRoutes) setCourierID(c *gin.Context) {
 	orderIDStr, courierIDStr := c.Query("order_id"), c.Query("courier_id")
 
 	courierID, err := uuid.Parse(courierIDStr)
 	if err != nil {
-		r.l.Error(err, "http - v1 - order - setCourierID - uuid.Parse(courier_id)")
+		requestLogger(c).Error(err, "http - v1 - order - setCourierID - uuid.Parse(courier_id)")
 		errorResponse(c, http.StatusBadRequest, "failed conversation courier_id to uuid")
 
 		return
@@ -284,7 +402,7 @@ func (r *orderRoutes) setCourierID(c *gin.Context) {
 
 	orderID, err := uuid.Parse(orderIDStr)
 	if err != nil {
-		r.l.Error(err, "http - v1 - order - setCourierID - uuid.Parse(order_id)")
+		requestLogger(c).Error(err, "http - v1 - order - setCourierID - uuid.Parse(order_id)")
 		errorResponse(c, http.StatusBadRequest, "failed conversation order_id to uuid")
 
 		return
@@ -292,8 +410,44 @@ func (r *orderRoutes) setCourierID(c *gin.Context) {
 
 	order, err := r.uc.SetCourierID(c.Request.Context(), orderID, courierID)
 	if err != nil {
-		r.l.Error(err, "http - v1 - order - setCourierID")
-		errorResponse(c, http.StatusInternalServerError, "order service problem")
+		requestLogger(c).Error(err, "http - v1 - order - setCourierID")
+		errorResponseFromError(c, err, "order service problem")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// @Summary     Unassign order
+// @Description Reset courier and distribution date of the order so the next assignment picks it up again
+// @ID          unassign-order
+// @Tags  	    orders
+// @Produce     json
+// @Param       order_id query string true "Order ID"
+// @Success     200 {object} entity.OrderResponse
+// @Failure     400 {object} response
+// @Failure     404 {object} response
+// @Failure     422 {object} response
+// @Failure     401 {object} response
+// @Failure     403 {object} response
+// @Failure     500 {object} response
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
+// @Router      /orders/unassign [put]
+func (r *orderRoutes) unassign(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Query("order_id"))
+	if err != nil {
+		requestLogger(c).Error(err, "http - v1 - order - unassign - uuid.Parse(order_id)")
+		errorResponse(c, http.StatusBadRequest, "failed conversation order_id to uuid")
+
+		return
+	}
+
+	order, err := r.uc.Unassign(c.Request.Context(), orderID)
+	if err != nil {
+		requestLogger(c).Error(err, "http - v1 - order - unassign")
+		errorResponseFromError(c, err, "order service problem")
 
 		return
 	}
@@ -301,6 +455,65 @@ func (r *orderRoutes) setCourierID(c *gin.Context) {
 	c.JSON(http.StatusOK, order)
 }
 
+// @Summary     Reassign orders of courier
+// @Description Move all open orders of one courier on the date to another courier
+// @ID          reassign-orders
+// @Tags  	    orders
+// @Produce     json
+// @Param       from_courier_id query string true "Courier ID to take the orders from"
+// @Param       to_courier_id query string true "Courier ID to give the orders to"
+// @Param       date query string false "Date"
+// @Success     200 {object} entity.CourierAssignment
+// @Failure     400 {object} response
+// @Failure     404 {object} response
+// @Failure     409 {object} response
+// @Failure     401 {object} response
+// @Failure     403 {object} response
+// @Failure     500 {object} response
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
+// @Router      /orders/reassign [put]
+func (r *orderRoutes) reassign(c *gin.Context) {
+	date := entity.Today()
+	if dateStr, ok := c.GetQuery("date"); ok {
+		layout := "2006-01-02"
+		parsedDate, err := time.Parse(layout, dateStr)
+		if err != nil {
+			requestLogger(c).Error(err, "http - v1 - order - reassign - time.Parse(date)")
+			errorResponse(c, http.StatusBadRequest, "failed conversation date to time")
+
+			return
+		}
+		date = parsedDate
+	}
+
+	fromCourierID, err := uuid.Parse(c.Query("from_courier_id"))
+	if err != nil {
+		requestLogger(c).Error(err, "http - v1 - order - reassign - uuid.Parse(from_courier_id)")
+		errorResponse(c, http.StatusBadRequest, "failed conversation from_courier_id to uuid")
+
+		return
+	}
+
+	toCourierID, err := uuid.Parse(c.Query("to_courier_id"))
+	if err != nil {
+		requestLogger(c).Error(err, "http - v1 - order - reassign - uuid.Parse(to_courier_id)")
+		errorResponse(c, http.StatusBadRequest, "failed conversation to_courier_id to uuid")
+
+		return
+	}
+
+	courierAssignment, err := r.uc.Reassign(c.Request.Context(), date, fromCourierID, toCourierID)
+	if err != nil {
+		requestLogger(c).Error(err, "http - v1 - order - reassign")
+		errorResponseFromError(c, err, "order service problem")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, courierAssignment)
+}
+
 type couriersAssignResponse struct {
 	Date     time.Time                   `json:"date" binding:"require"`
 	Couriers []*entity.CourierAssignment `json:"couriers" binding:"require"`
@@ -314,15 +527,19 @@ type couriersAssignResponse struct {
 // @Param       date query string false "Date"
 // @Success     200 {object} couriersAssignResponse
 // @Failure     400 {object} response
+// @Failure     401 {object} response
+// @Failure     403 {object} response
 // @Failure     500 {object} response
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
 // @Router      /orders/assign [post]
 func (r *orderRoutes) assign(c *gin.Context) {
-	date := time.Now().Truncate(24 * time.Hour)
+	date := entity.Today()
 	if dateStr, ok := c.GetQuery("date"); ok {
 		layout := "2006-01-02"
 		parsedDate, err := time.Parse(layout, dateStr)
 		if err != nil {
-			r.l.Error(err, "http - v1 - order - assign - time.Parse(date)")
+			requestLogger(c).Error(err, "http - v1 - order - assign - time.Parse(date)")
 			errorResponse(c, http.StatusBadRequest, "failed conversation date to time")
 
 			return
@@ -332,8 +549,8 @@ func (r *orderRoutes) assign(c *gin.Context) {
 
 	courierAssignments, err := r.uc.Assign(c.Request.Context(), date)
 	if err != nil {
-		r.l.Error(err, "http - v1 - order - assign")
-		errorResponse(c, http.StatusInternalServerError, "order service problem")
+		requestLogger(c).Error(err, "http - v1 - order - assign")
+		errorResponseFromError(c, err, "order service problem")
 
 		return
 	}
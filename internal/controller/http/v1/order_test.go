@@ -96,6 +96,45 @@ func TestValidateOrder(t *testing.T) {
 			},
 			expectedErr: errors.New("the order cost can't be less than zero"),
 		},
+		{
+			name: "address without regions",
+			in: v1.CreateOrderRequest{
+				Weight:        25,
+				DeliveryHours: []string{"10:00-20:00"},
+				Cost:          100,
+				Address:       &v1.AddressRequest{Address: "Lva Tolstogo St, 16", Latitude: coordinate(55.7339), Longitude: coordinate(37.5871)},
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "neither regions nor address",
+			in: v1.CreateOrderRequest{
+				Weight:        25,
+				DeliveryHours: []string{"10:00-20:00"},
+				Cost:          100,
+			},
+			expectedErr: errors.New("the order region or address is required"),
+		},
+		{
+			name: "address without coordinates",
+			in: v1.CreateOrderRequest{
+				Weight:        25,
+				DeliveryHours: []string{"10:00-20:00"},
+				Cost:          100,
+				Address:       &v1.AddressRequest{Address: "Lva Tolstogo St, 16", Longitude: coordinate(37.5871)},
+			},
+			expectedErr: errors.New("the latitude is required"),
+		},
+		{
+			name: "wrong longitude",
+			in: v1.CreateOrderRequest{
+				Weight:        25,
+				DeliveryHours: []string{"10:00-20:00"},
+				Cost:          100,
+				Address:       &v1.AddressRequest{Address: "Lva Tolstogo St, 16", Latitude: coordinate(55.7339), Longitude: coordinate(237.5871)},
+			},
+			expectedErr: errors.New("the longitude must be in -180..180"),
+		},
 	}
 
 	for _, tc := range testcases {
@@ -114,3 +153,7 @@ func TestValidateOrder(t *testing.T) {
 		})
 	}
 }
+
+func coordinate(c float64) *float64 {
+	return &c
+}
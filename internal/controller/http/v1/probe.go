@@ -0,0 +1,61 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/almostinf/order_delivery_service/internal/health"
+	"github.com/gin-gonic/gin"
+)
+
+const _readinessTimeout = 2 * time.Second
+
+// The errors of the checks are only logged, the probe is served without
+// authentication.
+type componentStatus struct {
+	Status string `json:"status" example:"ok"`
+}
+
+type readinessResponse struct {
+	Status     string                     `json:"status" example:"ok"`
+	Components map[string]componentStatus `json:"components"`
+}
+
+// The newProbeRoutes function registers the K8s probes. Liveness only tells
+// that the process serves requests, readiness runs every check.
+func newProbeRoutes(handler *gin.Engine, checkers []health.Checker) {
+	live := func(c *gin.Context) { c.Status(http.StatusOK) }
+
+	handler.GET("/healthz", live)
+	handler.GET("/livez", live)
+	handler.GET("/readyz", readiness(checkers))
+}
+
+func readiness(checkers []health.Checker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), _readinessTimeout)
+		defer cancel()
+
+		res := readinessResponse{
+			Status:     "ok",
+			Components: make(map[string]componentStatus, len(checkers)),
+		}
+		status := http.StatusOK
+
+		for _, checker := range checkers {
+			if err := checker.Check(ctx); err != nil {
+				requestLogger(c).With("component", checker.Name()).Error(err, "http - v1 - probe - readiness")
+				res.Components[checker.Name()] = componentStatus{Status: "fail"}
+				res.Status = "fail"
+				status = http.StatusServiceUnavailable
+
+				continue
+			}
+
+			res.Components[checker.Name()] = componentStatus{Status: "ok"}
+		}
+
+		c.JSON(status, res)
+	}
+}
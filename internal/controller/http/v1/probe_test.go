@@ -0,0 +1,77 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/almostinf/order_delivery_service/internal/health"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeChecker struct {
+	name string
+	err  error
+}
+
+func (c fakeChecker) Name() string                  { return c.name }
+func (c fakeChecker) Check(_ context.Context) error { return c.err }
+
+func TestReadiness(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name           string
+		checkers       []health.Checker
+		expectedStatus int
+		expected       readinessResponse
+	}{
+		{
+			name:           "ready",
+			checkers:       []health.Checker{fakeChecker{name: "postgres"}, fakeChecker{name: "migrations"}},
+			expectedStatus: http.StatusOK,
+			expected: readinessResponse{
+				Status: "ok",
+				Components: map[string]componentStatus{
+					"postgres":   {Status: "ok"},
+					"migrations": {Status: "ok"},
+				},
+			},
+		},
+		{
+			name:           "migrations behind",
+			checkers:       []health.Checker{fakeChecker{name: "postgres"}, fakeChecker{name: "migrations", err: errors.New("database version 1, expected 2")}},
+			expectedStatus: http.StatusServiceUnavailable,
+			expected: readinessResponse{
+				Status: "fail",
+				Components: map[string]componentStatus{
+					"postgres":   {Status: "ok"},
+					"migrations": {Status: "fail"},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := gin.New()
+			newProbeRoutes(handler, tc.checkers)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			var res readinessResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			require.Equal(t, tc.expectedStatus, w.Code)
+			require.Equal(t, tc.expected, res)
+		})
+	}
+}
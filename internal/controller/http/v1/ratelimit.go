@@ -0,0 +1,86 @@
+package v1
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/almostinf/order_delivery_service/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// The NewRateLimiterMiddleware function limits the requests of every client
+// separately. Authenticated clients are told apart by the principal, the
+// others by IP. Routes are keyed as "METHOD /v1/path" in the overrides and
+// get their own bucket, the rest share the default one. Requests are let
+// through when the store fails, so its outage doesn't take the API down.
+func NewRateLimiterMiddleware(store ratelimit.Store, defaultRule ratelimit.Rule, routes map[string]ratelimit.Rule) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := rateLimitClientKey(c)
+		rule := defaultRule
+
+		route := c.Request.Method + " " + c.FullPath()
+		if routeRule, ok := routes[route]; ok {
+			key += "|" + route
+			rule = routeRule
+		}
+
+		res, err := store.Take(c.Request.Context(), key, rule)
+		if err != nil {
+			requestLogger(c).Error(err, "http - v1 - rateLimiter - store.Take")
+			c.Next()
+
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(res.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+
+		if !res.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+			errorResponse(c, http.StatusTooManyRequests, "rate limit exceeded")
+
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// The NewIPRateLimiterMiddleware function limits the requests of every IP
+// before authentication, so the clients failing it are limited too. The IP
+// is taken from X-Forwarded-For only behind the trusted proxies of the engine.
+func NewIPRateLimiterMiddleware(store ratelimit.Store, rule ratelimit.Rule) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		res, err := store.Take(c.Request.Context(), "remote:"+c.ClientIP(), rule)
+		if err != nil {
+			requestLogger(c).Error(err, "http - v1 - ipRateLimiter - store.Take")
+			c.Next()
+
+			return
+		}
+
+		if !res.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+			errorResponse(c, http.StatusTooManyRequests, "rate limit exceeded")
+
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func rateLimitClientKey(c *gin.Context) string {
+	if principal := principalFromContext(c); principal != nil {
+		return "principal:" + principal.Subject
+	}
+
+	return "ip:" + c.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
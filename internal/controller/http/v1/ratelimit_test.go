@@ -0,0 +1,56 @@
+package v1_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	v1 "github.com/almostinf/order_delivery_service/internal/controller/http/v1"
+	"github.com/almostinf/order_delivery_service/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiterMiddleware(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+
+	handler := gin.New()
+	handler.Use(v1.NewRateLimiterMiddleware(
+		ratelimit.NewMemoryStore(time.Minute),
+		ratelimit.Rule{RPS: 1, Burst: 2},
+		map[string]ratelimit.Rule{"POST /assign": {RPS: 0.1, Burst: 1}},
+	))
+	handler.GET("/orders", func(c *gin.Context) { c.Status(http.StatusOK) })
+	handler.POST("/assign", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	do := func(method, path, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		return w
+	}
+
+	w := do(http.MethodPost, "/assign", "10.0.0.1")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "1", w.Header().Get("RateLimit-Limit"))
+	require.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+
+	w = do(http.MethodPost, "/assign", "10.0.0.1")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Equal(t, "10", w.Header().Get("Retry-After"))
+
+	// The route override doesn't use the default bucket
+	w = do(http.MethodGet, "/orders", "10.0.0.1")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	require.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+
+	// Other clients aren't throttled
+	w = do(http.MethodPost, "/assign", "10.0.0.2")
+	require.Equal(t, http.StatusOK, w.Code)
+}
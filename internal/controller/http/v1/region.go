@@ -0,0 +1,230 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/almostinf/order_delivery_service/internal/auth"
+	"github.com/almostinf/order_delivery_service/internal/entity"
+	"github.com/almostinf/order_delivery_service/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+type regionRoutes struct {
+	uc usecase.RegionUseCase
+}
+
+func newRegionRoutes(handler *gin.RouterGroup, z *authorizer, uc usecase.RegionUseCase) {
+	r := &regionRoutes{uc}
+
+	h := handler.Group("/regions")
+	{
+		readers := z.allow(auth.RoleAdmin, auth.RoleDispatcher, auth.RoleIntegration)
+
+		h.GET("/", readers, r.getAll)
+		h.GET("/:region_id", readers, r.get)
+		h.PUT("/:region_id", z.allow(auth.RoleAdmin), r.put)
+		h.DELETE("/:region_id", z.allow(auth.RoleAdmin), r.delete)
+	}
+}
+
+type getAllRegionsResponse struct {
+	Regions []*entity.Region `json:"regions"`
+}
+
+// @Summary     Get All Regions
+// @Description Get the region catalog from Postgres
+// @ID          get-all-regions
+// @Tags  	    regions
+// @Produce     json
+// @Success     200 {object} getAllRegionsResponse
+// @Failure     401 {object} response
+// @Failure     403 {object} response
+// @Failure     500 {object} response
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
+// @Router      /regions/ [get]
+func (r *regionRoutes) getAll(c *gin.Context) {
+	regions, err := r.uc.GetAll(c.Request.Context())
+	if err != nil {
+		requestLogger(c).Error(err, "http - v1 - region - getAll - GetAll")
+		errorResponseFromError(c, err, "region service problems")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, getAllRegionsResponse{Regions: regions})
+}
+
+// @Summary     Get Region by ID in path
+// @Description Get Region by ID from Postgres
+// @ID          get-region-by-id
+// @Tags  	    regions
+// @Produce     json
+// @Param       region_id path int true "Region ID"
+// @Success     200 {object} entity.Region
+// @Failure     400 {object} response
+// @Failure     401 {object} response
+// @Failure     403 {object} response
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
+// @Router      /regions/{region_id} [get]
+func (r *regionRoutes) get(c *gin.Context) {
+	id, ok := regionIDParam(c)
+	if !ok {
+		return
+	}
+
+	region, err := r.uc.Get(c.Request.Context(), id)
+	if err != nil {
+		requestLogger(c).Error(err, "http - v1 - region - get - Get")
+		errorResponseFromError(c, err, "region service problems")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, region)
+}
+
+type PutRegionRequest struct {
+	Name      string          `json:"name" binding:"required"`
+	Polygon   *entity.Polygon `json:"polygon"`
+	Neighbors []int           `json:"neighbors"`
+}
+
+// @Summary     Create or replace Region
+// @Description Create or replace the Region, the neighbors listed get the region as their neighbor too
+// @ID          put-region
+// @Tags  	    regions
+// @Accept      json
+// @Produce     json
+// @Param       region_id path int true "Region ID"
+// @Param       request body PutRegionRequest true "Region object"
+// @Success     200 {object} entity.Region
+// @Failure     400 {object} response
+// @Failure     401 {object} response
+// @Failure     403 {object} response
+// @Failure     500 {object} response
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
+// @Router      /regions/{region_id} [put]
+func (r *regionRoutes) put(c *gin.Context) {
+	id, ok := regionIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req PutRegionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		requestLogger(c).Error(err, "http - v1 - region - put")
+		validationErrorResponse(c, bindViolations(err))
+
+		return
+	}
+
+	if violations := regionViolations(id, req); len(violations) > 0 {
+		requestLogger(c).Error(violations[0], "http - v1 - region - put")
+		validationErrorResponse(c, violations)
+
+		return
+	}
+
+	region, err := r.uc.Put(c.Request.Context(), &entity.Region{
+		RegionID:  id,
+		Name:      req.Name,
+		Polygon:   req.Polygon,
+		Neighbors: req.Neighbors,
+	})
+	if err != nil {
+		requestLogger(c).Error(err, "http - v1 - region - put - Put")
+		errorResponseFromError(c, err, "region service problems")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, region)
+}
+
+// @Summary     Delete Region
+// @Description Delete the Region no courier or order refers to
+// @ID          delete-region
+// @Tags  	    regions
+// @Param       region_id path int true "Region ID"
+// @Success     204
+// @Failure     400 {object} response
+// @Failure     401 {object} response
+// @Failure     403 {object} response
+// @Failure     404 {object} response
+// @Failure     409 {object} response
+// @Failure     500 {object} response
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
+// @Router      /regions/{region_id} [delete]
+func (r *regionRoutes) delete(c *gin.Context) {
+	id, ok := regionIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := r.uc.Delete(c.Request.Context(), id); err != nil {
+		requestLogger(c).Error(err, "http - v1 - region - delete - Delete")
+		errorResponseFromError(c, err, "region service problems")
+
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func regionIDParam(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("region_id"))
+	if err != nil || id < 0 {
+		requestLogger(c).Error(errors.New("invalid region_id"), "http - v1 - region - regionIDParam")
+		errorResponse(c, http.StatusBadRequest, "the region_id must be a non-negative integer")
+
+		return 0, false
+	}
+
+	return id, true
+}
+
+// regionReference is a region id of a request body and the field it was
+// found in.
+type regionReference struct {
+	index  int
+	field  string
+	region int
+}
+
+// unknownRegionViolations reports the references to the regions missing
+// from the catalog, so a batch isn't created partially.
+func unknownRegionViolations(ctx context.Context, uc usecase.RegionUseCase, refs []regionReference) ([]Violation, error) {
+	ids := make([]int, 0, len(refs))
+	for _, ref := range refs {
+		ids = append(ids, ref.region)
+	}
+
+	unknown, err := uc.Unknown(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	unknownSet := make(map[int]struct{}, len(unknown))
+	for _, id := range unknown {
+		unknownSet[id] = struct{}{}
+	}
+
+	violations := make([]Violation, 0)
+	for _, ref := range refs {
+		if _, ok := unknownSet[ref.region]; ok {
+			violations = append(violations, Violation{ref.index, ref.field, _violationNotFound, fmt.Sprintf("region %d doesn't exist", ref.region)})
+		}
+	}
+
+	return violations, nil
+}
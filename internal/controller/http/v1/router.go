@@ -1,30 +1,16 @@
 package v1
 
 import (
-	"net/http"
-	"os"
-	"time"
-
 	_ "github.com/almostinf/order_delivery_service/docs"
+	"github.com/almostinf/order_delivery_service/internal/auth"
+	"github.com/almostinf/order_delivery_service/internal/health"
 	"github.com/almostinf/order_delivery_service/internal/usecase"
 	"github.com/almostinf/order_delivery_service/pkg/logger"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
-	"golang.org/x/time/rate"
 )
 
-func NewRateLimiterMiddleware(r rate.Limit, b int) gin.HandlerFunc {
-	limiter := rate.NewLimiter(r, b)
-	return func(c *gin.Context) {
-		if !limiter.Allow() {
-			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
-			return
-		}
-		c.Next()
-	}
-}
-
 // NewRouter -.
 // Swagger spec:
 // @title       Order Delivery Service API
@@ -32,28 +18,50 @@ func NewRateLimiterMiddleware(r rate.Limit, b int) gin.HandlerFunc {
 // @version     1.0
 // @host        localhost:8080
 // @BasePath    /v1
-func NewRouter(handler *gin.Engine, l logger.Interface, c usecase.CourierUseCase, o usecase.OrderUseCase) {
+// @securityDefinitions.apikey ApiKeyAuth
+// @in          header
+// @name        X-API-Key
+// @securityDefinitions.apikey BearerAuth
+// @in          header
+// @name        Authorization
+func NewRouter(handler *gin.Engine, l logger.Interface, authenticator auth.Authenticator, ipRateLimiter, rateLimiter gin.HandlerFunc, checkers []health.Checker, c usecase.CourierUseCase, o usecase.OrderUseCase, a usecase.AnalyticsUseCase, rg usecase.RegionUseCase) {
 	// Options
-	handler.Use(gin.Logger())
-	handler.Use(gin.Recovery())
-	isRateLimit, ok := os.LookupEnv("RATE_LIMITER")
-	if !ok {
-		l.Fatal("RATE_LIMITER variable is not set")
-	}
+	handler.Use(NewTracingMiddleware())
+	handler.Use(NewRequestLoggerMiddleware(l))
+	// The metrics come before the recovery to count the panics as 500
+	handler.Use(NewMetricsMiddleware())
+	handler.Use(NewRecoveryMiddleware())
 
-	if isRateLimit == "ENABLE" { // I disable rate limiter for integration tests
-		handler.Use(NewRateLimiterMiddleware(rate.Every(time.Second), 10))
-	}
+	// K8s probes
+	newProbeRoutes(handler, checkers)
 
-	// K8s probe
-	handler.GET("/healthz", func(c *gin.Context) { c.Status(http.StatusOK) })
+	// Prometheus metrics
+	newMetricsRoutes(handler)
 
 	handler.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	// Routers
-	h := handler.Group("/v1")
+	// A nil authenticator leaves every route public
+	z := &authorizer{authenticator}
+
+	// The IP limit comes before authentication to also limit the requests
+	// with bad credentials, the client limits after it to count requests per
+	// client. Nil rate limiters disable them
+	middlewares := make([]gin.HandlerFunc, 0, 3)
+	if ipRateLimiter != nil {
+		middlewares = append(middlewares, ipRateLimiter)
+	}
+	middlewares = append(middlewares, z.authenticate())
+	if rateLimiter != nil {
+		middlewares = append(middlewares, rateLimiter)
+	}
+
+	h := handler.Group("/v1", middlewares...)
 	{
-		newCourierRoutes(h, c, l)
-		newOrderRoutes(h, o, l)
+		newCourierRoutes(h, z, c, rg)
+		newOrderRoutes(h, z, o, rg)
+		newAnalyticsRoutes(h, z, a)
+		newMeRoutes(h, z, c, o)
+		newRegionRoutes(h, z, rg)
 	}
 }
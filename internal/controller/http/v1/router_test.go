@@ -0,0 +1,173 @@
+package v1_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/almostinf/order_delivery_service/internal/auth"
+	v1 "github.com/almostinf/order_delivery_service/internal/controller/http/v1"
+	"github.com/almostinf/order_delivery_service/internal/entity"
+	mocks "github.com/almostinf/order_delivery_service/internal/mocks/repo"
+	"github.com/almostinf/order_delivery_service/internal/ratelimit"
+	"github.com/almostinf/order_delivery_service/internal/usecase"
+	"github.com/almostinf/order_delivery_service/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouterRoles(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+
+	mockCtrl := gomock.NewController(t)
+
+	courierRepo := mocks.NewMockCourier(mockCtrl)
+	courierRepo.EXPECT().GetAll(gomock.Any(), gomock.Any(), gomock.Any()).Return([]*entity.CourierResponse{}, nil).AnyTimes()
+	courierRepo.EXPECT().GetAssignments(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]*entity.CourierAssignment{}, nil).AnyTimes()
+
+	orderRepo := mocks.NewMockOrder(mockCtrl)
+	orderRepo.EXPECT().Assign(gomock.Any(), gomock.Any()).Return(&entity.AssignmentRun{}, nil).AnyTimes()
+
+	regionRepo := mocks.NewMockRegion(mockCtrl)
+	regionRepo.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	keys := map[string]string{"admin-key": "admin", "dispatcher-key": "dispatcher", "integration-key": "integration"}
+	apiKeys, err := auth.NewAPIKeyAuthenticator(keys)
+	require.NoError(t, err)
+
+	jwt := auth.NewJWTAuthenticator([]byte("secret"))
+	courierToken, err := jwt.Sign(auth.Claims{Subject: "courier", Role: auth.RoleCourier, CourierID: uuid.New(), ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	require.NoError(t, err)
+
+	handler := gin.New()
+	v1.NewRouter(handler, logger.New("error"), auth.Chain{apiKeys, jwt}, nil, nil, nil,
+		*usecase.NewCourierUseCase(courierRepo), *usecase.NewOrderUseCase(orderRepo), *usecase.NewAnalyticsUseCase(mocks.NewMockAnalytics(mockCtrl)), *usecase.NewRegionUseCase(regionRepo))
+
+	credentials := map[string]func(r *http.Request){
+		"anonymous":   func(r *http.Request) {},
+		"admin":       func(r *http.Request) { r.Header.Set("X-API-Key", "admin-key") },
+		"dispatcher":  func(r *http.Request) { r.Header.Set("X-API-Key", "dispatcher-key") },
+		"integration": func(r *http.Request) { r.Header.Set("X-API-Key", "integration-key") },
+		"courier":     func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+courierToken) },
+		"invalid key": func(r *http.Request) { r.Header.Set("X-API-Key", "unknown-key") },
+	}
+
+	testcases := []struct {
+		method string
+		path   string
+		codes  map[string]int // mapping: role -> expected status
+	}{
+		{
+			method: http.MethodGet,
+			path:   "/v1/couriers/",
+			codes:  map[string]int{"admin": 200, "dispatcher": 200, "integration": 200, "courier": 403},
+		},
+		{
+			method: http.MethodPost,
+			path:   "/v1/orders/assign",
+			codes:  map[string]int{"admin": 200, "dispatcher": 200, "integration": 403, "courier": 403},
+		},
+		{
+			method: http.MethodDelete,
+			path:   "/v1/regions/1",
+			codes:  map[string]int{"admin": 204, "dispatcher": 403, "integration": 403, "courier": 403},
+		},
+		{
+			method: http.MethodGet,
+			path:   "/v1/me/orders",
+			codes:  map[string]int{"admin": 403, "dispatcher": 403, "integration": 403, "courier": 200},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+
+		// Requests without valid credentials never reach the role check
+		tc.codes["anonymous"] = http.StatusUnauthorized
+		tc.codes["invalid key"] = http.StatusUnauthorized
+
+		for role, code := range tc.codes {
+			role, code := role, code
+
+			t.Run(tc.method+" "+tc.path+" as "+role, func(t *testing.T) {
+				t.Parallel()
+
+				req := httptest.NewRequest(tc.method, tc.path, nil)
+				credentials[role](req)
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, req)
+
+				require.Equal(t, code, w.Code, w.Body.String())
+			})
+		}
+	}
+}
+
+func TestRouterLimitsFailedAuthentication(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+
+	apiKeys, err := auth.NewAPIKeyAuthenticator(map[string]string{"admin-key": "admin"})
+	require.NoError(t, err)
+
+	store := ratelimit.NewMemoryStore(time.Minute)
+	ipRateLimiter := v1.NewIPRateLimiterMiddleware(store, ratelimit.Rule{RPS: 0.01, Burst: 3})
+	rateLimiter := v1.NewRateLimiterMiddleware(store, ratelimit.Rule{RPS: 1, Burst: 10}, nil)
+
+	mockCtrl := gomock.NewController(t)
+
+	// No trusted proxies, so X-Forwarded-For can't give every request a new IP
+	handler := gin.New()
+	require.NoError(t, handler.SetTrustedProxies(nil))
+	v1.NewRouter(handler, logger.New("error"), apiKeys, ipRateLimiter, rateLimiter, nil,
+		*usecase.NewCourierUseCase(mocks.NewMockCourier(mockCtrl)), *usecase.NewOrderUseCase(mocks.NewMockOrder(mockCtrl)),
+		*usecase.NewAnalyticsUseCase(mocks.NewMockAnalytics(mockCtrl)), *usecase.NewRegionUseCase(mocks.NewMockRegion(mockCtrl)))
+
+	do := func(ip, forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodGet, "/v1/couriers/", nil)
+		req.RemoteAddr = ip + ":1234"
+		req.Header.Set("X-API-Key", "guessed-key")
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		return w.Code
+	}
+
+	for i := 0; i < 3; i++ {
+		require.Equal(t, http.StatusUnauthorized, do("10.0.0.1", fmt.Sprintf("172.16.0.%d", i)))
+	}
+	require.Equal(t, http.StatusTooManyRequests, do("10.0.0.1", "172.16.0.100"))
+
+	// Other clients aren't throttled
+	require.Equal(t, http.StatusUnauthorized, do("10.0.0.2", ""))
+}
+
+func TestRouterCountsPanics(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+
+	mockCtrl := gomock.NewController(t)
+
+	handler := gin.New()
+	v1.NewRouter(handler, logger.New("error"), nil, nil, nil, nil,
+		*usecase.NewCourierUseCase(mocks.NewMockCourier(mockCtrl)), *usecase.NewOrderUseCase(mocks.NewMockOrder(mockCtrl)),
+		*usecase.NewAnalyticsUseCase(mocks.NewMockAnalytics(mockCtrl)), *usecase.NewRegionUseCase(mocks.NewMockRegion(mockCtrl)))
+	handler.GET("/v1/panic", func(c *gin.Context) { panic("boom") })
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/panic", nil))
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Contains(t, w.Body.String(), `order_delivery_http_requests_total{method="GET",route="/v1/panic",status="500"} 1`)
+}
@@ -0,0 +1,253 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/almostinf/order_delivery_service/internal/entity"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Violation codes
+const (
+	_violationRequired      = "required"
+	_violationInvalidValue  = "invalid_value"
+	_violationInvalidFormat = "invalid_format"
+	_violationOutOfRange    = "out_of_range"
+	_violationInvalidType   = "invalid_type"
+	_violationInvalidJSON   = "invalid_json"
+	_violationNotFound      = "not_found"
+)
+
+// The Violation struct describes a single invalid field of a request body.
+// Index is the position of the item in the batch or -1 when the violation
+// doesn't belong to a particular item.
+type Violation struct {
+	Index   int    `json:"index" example:"0"`
+	Field   string `json:"field" example:"couriers[0].working_hours[1]"`
+	Code    string `json:"code" example:"invalid_format"`
+	Message string `json:"message" example:"invalid working time: invalid time range format"`
+}
+
+func (v Violation) Error() string {
+	return v.Message
+}
+
+// The validationErrorResponse function aborts the current request with
+// all violations found in the request body.
+func validationErrorResponse(c *gin.Context, violations []Violation) {
+	c.AbortWithStatusJSON(http.StatusBadRequest, response{
+		Error:      "invalid request body",
+		Code:       _codeValidation,
+		Violations: violations,
+	})
+}
+
+// The bindViolations function converts an error of ShouldBindJSON into
+// violations.
+func bindViolations(err error) []Violation {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return []Violation{{
+			Index:   -1,
+			Field:   typeErr.Field,
+			Code:    _violationInvalidType,
+			Message: fmt.Sprintf("expected %s, got %s", typeErr.Type, typeErr.Value),
+		}}
+	}
+
+	return []Violation{{
+		Index:   -1,
+		Code:    _violationInvalidJSON,
+		Message: err.Error(),
+	}}
+}
+
+func missingKeyViolation(key string) Violation {
+	return Violation{
+		Index:   -1,
+		Field:   key,
+		Code:    _violationRequired,
+		Message: fmt.Sprintf("%s is required", key),
+	}
+}
+
+func courierViolations(index int, courier CreateCourierRequest) []Violation {
+	var violations []Violation
+	path := fmt.Sprintf("couriers[%d]", index)
+
+	if courier.CourierType != "FOOT" && courier.CourierType != "BIKE" && courier.CourierType != "AUTO" {
+		violations = append(violations, Violation{index, path + ".courier_type", _violationInvalidValue, "invalid courier type format"})
+	}
+
+	if len(courier.Regions) == 0 {
+		violations = append(violations, Violation{index, path + ".regions", _violationRequired, "invalid courier regions format (regions are empty)"})
+	}
+
+	for i, region := range courier.Regions {
+		if region < 0 {
+			violations = append(violations, Violation{index, fmt.Sprintf("%s.regions[%d]", path, i), _violationOutOfRange, "invalid courier region format"})
+		}
+	}
+
+	for i, workingTime := range courier.WorkingHours {
+		if err := parseTimeRange(workingTime); err != nil {
+			violations = append(violations, Violation{index, fmt.Sprintf("%s.working_hours[%d]", path, i), _violationInvalidFormat, fmt.Sprintf("invalid working time: %s", err)})
+		}
+	}
+
+	return violations
+}
+
+func orderViolations(index int, order CreateOrderRequest) []Violation {
+	var violations []Violation
+	path := fmt.Sprintf("orders[%d]", index)
+
+	if order.Weight <= 0 {
+		violations = append(violations, Violation{index, path + ".weight", _violationOutOfRange, "the order weight can't be less than zero"})
+	}
+
+	if order.Regions < 0 {
+		violations = append(violations, Violation{index, path + ".regions", _violationOutOfRange, "the order region can't be less than zero"})
+	} else if order.Regions == 0 && order.Address == nil {
+		violations = append(violations, Violation{index, path + ".regions", _violationRequired, "the order region or address is required"})
+	}
+
+	if order.Address != nil {
+		violations = append(violations, addressViolations(index, path+".address", *order.Address)...)
+	}
+
+	for i, deliveryHours := range order.DeliveryHours {
+		if err := parseTimeRange(deliveryHours); err != nil {
+			violations = append(violations, Violation{index, fmt.Sprintf("%s.delivery_hours[%d]", path, i), _violationInvalidFormat, fmt.Sprintf("invalid delivery hours: %s", err)})
+		}
+	}
+
+	if order.Cost < 0 {
+		violations = append(violations, Violation{index, path + ".cost", _violationOutOfRange, "the order cost can't be less than zero"})
+	}
+
+	return violations
+}
+
+func addressViolations(index int, path string, address AddressRequest) []Violation {
+	var violations []Violation
+
+	if address.Address == "" {
+		violations = append(violations, Violation{index, path + ".address", _violationRequired, "the address is required"})
+	}
+
+	if address.Latitude == nil {
+		violations = append(violations, Violation{index, path + ".latitude", _violationRequired, "the latitude is required"})
+	} else if *address.Latitude < -90 || *address.Latitude > 90 {
+		violations = append(violations, Violation{index, path + ".latitude", _violationOutOfRange, "the latitude must be in -90..90"})
+	}
+
+	if address.Longitude == nil {
+		violations = append(violations, Violation{index, path + ".longitude", _violationRequired, "the longitude is required"})
+	} else if *address.Longitude < -180 || *address.Longitude > 180 {
+		violations = append(violations, Violation{index, path + ".longitude", _violationOutOfRange, "the longitude must be in -180..180"})
+	}
+
+	return violations
+}
+
+func regionViolations(id int, region PutRegionRequest) []Violation {
+	var violations []Violation
+
+	if region.Name == "" {
+		violations = append(violations, Violation{-1, "name", _violationRequired, "the region name is required"})
+	}
+
+	for i, neighbor := range region.Neighbors {
+		field := fmt.Sprintf("neighbors[%d]", i)
+		if neighbor < 0 {
+			violations = append(violations, Violation{-1, field, _violationOutOfRange, "the neighbor region can't be less than zero"})
+		} else if neighbor == id {
+			violations = append(violations, Violation{-1, field, _violationInvalidValue, "the region can't be its own neighbor"})
+		}
+	}
+
+	if region.Polygon != nil {
+		violations = append(violations, polygonViolations("polygon", *region.Polygon)...)
+	}
+
+	return violations
+}
+
+// polygonViolations checks the GeoJSON polygon: every ring has at least
+// four positions, is closed and the positions are valid [longitude, latitude].
+func polygonViolations(path string, polygon entity.Polygon) []Violation {
+	var violations []Violation
+
+	if polygon.Type != "Polygon" {
+		violations = append(violations, Violation{-1, path + ".type", _violationInvalidValue, fmt.Sprintf("the polygon type must be Polygon, got %q", polygon.Type)})
+	}
+
+	if len(polygon.Coordinates) == 0 {
+		violations = append(violations, Violation{-1, path + ".coordinates", _violationRequired, "the polygon has no rings"})
+	}
+
+	for i, ring := range polygon.Coordinates {
+		ringPath := fmt.Sprintf("%s.coordinates[%d]", path, i)
+
+		if len(ring) < 4 {
+			violations = append(violations, Violation{-1, ringPath, _violationInvalidFormat, "a ring must have at least four positions"})
+			continue
+		}
+
+		valid := true
+		for j, position := range ring {
+			if len(position) < 2 || position[0] < -180 || position[0] > 180 || position[1] < -90 || position[1] > 90 {
+				violations = append(violations, Violation{-1, fmt.Sprintf("%s[%d]", ringPath, j), _violationOutOfRange, "a position must be [longitude, latitude] in degrees"})
+				valid = false
+			}
+		}
+
+		if first, last := ring[0], ring[len(ring)-1]; valid && (first[0] != last[0] || first[1] != last[1]) {
+			violations = append(violations, Violation{-1, ringPath, _violationInvalidFormat, "a ring must end with its first position"})
+		}
+	}
+
+	return violations
+}
+
+func completeInfoViolations(index int, completeInfo entity.CompleteInfo) []Violation {
+	var violations []Violation
+	path := fmt.Sprintf("complete_info[%d]", index)
+
+	if completeInfo.CourierID == uuid.Nil {
+		violations = append(violations, Violation{index, path + ".courier_id", _violationRequired, "the courier_id is required"})
+	}
+
+	if completeInfo.OrderID == uuid.Nil {
+		violations = append(violations, Violation{index, path + ".order_id", _violationRequired, "the order_id is required"})
+	}
+
+	if completeInfo.CompleteTime.IsZero() {
+		violations = append(violations, Violation{index, path + ".complete_time", _violationRequired, "the complete_time is required"})
+	}
+
+	return violations
+}
+
+// ValidateCompleteInfo returns the first violation of the complete info item.
+func ValidateCompleteInfo(completeInfo entity.CompleteInfo) error {
+	if violations := completeInfoViolations(0, completeInfo); len(violations) > 0 {
+		return violations[0]
+	}
+
+	return nil
+}
+
+// ValidateRegionRequest returns the first violation of the region.
+func ValidateRegionRequest(id int, region PutRegionRequest) error {
+	if violations := regionViolations(id, region); len(violations) > 0 {
+		return violations[0]
+	}
+
+	return nil
+}
@@ -0,0 +1,165 @@
+package v1_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	v1 "github.com/almostinf/order_delivery_service/internal/controller/http/v1"
+	"github.com/almostinf/order_delivery_service/internal/entity"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCompleteInfo(t *testing.T) {
+	t.Parallel()
+
+	courierID, orderID := uuid.New(), uuid.New()
+	completeTime := time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	testcases := []struct {
+		name        string
+		in          entity.CompleteInfo
+		expectedErr error
+	}{
+		{
+			name: "success",
+			in: entity.CompleteInfo{
+				CourierID:    courierID,
+				OrderID:      orderID,
+				CompleteTime: completeTime,
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "missing courier id",
+			in: entity.CompleteInfo{
+				OrderID:      orderID,
+				CompleteTime: completeTime,
+			},
+			expectedErr: errors.New("the courier_id is required"),
+		},
+		{
+			name: "missing order id",
+			in: entity.CompleteInfo{
+				CourierID:    courierID,
+				CompleteTime: completeTime,
+			},
+			expectedErr: errors.New("the order_id is required"),
+		},
+		{
+			name: "missing complete time",
+			in: entity.CompleteInfo{
+				CourierID: courierID,
+				OrderID:   orderID,
+			},
+			expectedErr: errors.New("the complete_time is required"),
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := v1.ValidateCompleteInfo(tc.in)
+
+			if tc.expectedErr != nil {
+				require.EqualError(t, err, tc.expectedErr.Error())
+			} else {
+				require.ErrorIs(t, tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateCourierRequestViolation(t *testing.T) {
+	t.Parallel()
+
+	err := v1.ValidateCourierRequest(v1.CreateCourierRequest{
+		CourierType:  "FOOT",
+		Regions:      []int{1, 2},
+		WorkingHours: []string{"10:00-12:00", "12:00-11:00"},
+	})
+
+	var violation v1.Violation
+	require.ErrorAs(t, err, &violation)
+	require.Equal(t, v1.Violation{
+		Index:   0,
+		Field:   "couriers[0].working_hours[1]",
+		Code:    "invalid_format",
+		Message: "invalid working time: the end time must not be less or equal than the start time",
+	}, violation)
+}
+
+func TestValidateRegionRequest(t *testing.T) {
+	t.Parallel()
+
+	square := func(ring ...[]float64) *entity.Polygon {
+		return &entity.Polygon{Type: "Polygon", Coordinates: [][][]float64{ring}}
+	}
+
+	testcases := []struct {
+		name        string
+		in          v1.PutRegionRequest
+		expectedErr error
+	}{
+		{
+			name: "success",
+			in: v1.PutRegionRequest{
+				Name:      "Center",
+				Polygon:   square([]float64{37.6, 55.7}, []float64{37.7, 55.7}, []float64{37.7, 55.8}, []float64{37.6, 55.7}),
+				Neighbors: []int{2, 3},
+			},
+			expectedErr: nil,
+		},
+		{
+			name:        "missing name",
+			in:          v1.PutRegionRequest{Neighbors: []int{2}},
+			expectedErr: errors.New("the region name is required"),
+		},
+		{
+			name:        "own neighbor",
+			in:          v1.PutRegionRequest{Name: "Center", Neighbors: []int{2, 1}},
+			expectedErr: errors.New("the region can't be its own neighbor"),
+		},
+		{
+			name: "open ring",
+			in: v1.PutRegionRequest{
+				Name:    "Center",
+				Polygon: square([]float64{37.6, 55.7}, []float64{37.7, 55.7}, []float64{37.7, 55.8}, []float64{37.6, 55.8}),
+			},
+			expectedErr: errors.New("a ring must end with its first position"),
+		},
+		{
+			name: "latitude out of range",
+			in: v1.PutRegionRequest{
+				Name:    "Center",
+				Polygon: square([]float64{37.6, 95.7}, []float64{37.7, 55.7}, []float64{37.7, 55.8}, []float64{37.6, 95.7}),
+			},
+			expectedErr: errors.New("a position must be [longitude, latitude] in degrees"),
+		},
+		{
+			name:        "not a polygon",
+			in:          v1.PutRegionRequest{Name: "Center", Polygon: &entity.Polygon{Type: "Point"}},
+			expectedErr: errors.New(`the polygon type must be Polygon, got "Point"`),
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := v1.ValidateRegionRequest(1, tc.in)
+
+			if tc.expectedErr != nil {
+				require.EqualError(t, err, tc.expectedErr.Error())
+			} else {
+				require.ErrorIs(t, tc.expectedErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"time"
+)
+
+type CourierAnalyticsFilter struct {
+	StartDate   time.Time
+	EndDate     time.Time
+	Region      *int
+	CourierType string
+	SortBy      string
+	Limit       int
+	Offset      int
+}
+
+type CourierAnalytics struct {
+	CourierResponse
+	Rank            int     `json:"rank"`
+	Rating          int     `json:"rating"`
+	Earnings        int     `json:"earnings"`
+	CompletedOrders int     `json:"completed_orders"`
+	OnTimeRate      float64 `json:"on_time_rate"`
+}
+
+type OrderAnalyticsFilter struct {
+	StartDate   time.Time
+	EndDate     time.Time
+	Bucket      string
+	Region      *int
+	CourierType string
+}
+
+type OrderAnalytics struct {
+	BucketStart          time.Time `json:"bucket_start"`
+	Region               int       `json:"region"`
+	CourierType          string    `json:"courier_type"`
+	CreatedOrders        int       `json:"created_orders"`
+	AssignedOrders       int       `json:"assigned_orders"`
+	CompletedOrders      int       `json:"completed_orders"`
+	AvgCompletionMinutes float64   `json:"avg_completion_minutes"`
+	SLABreaches          int       `json:"sla_breaches"`
+	EarlyDeliveries      int       `json:"early_deliveries"`
+	LateDeliveries       int       `json:"late_deliveries"`
+}
@@ -21,6 +21,7 @@ type CourierResponse struct {
 
 type CourierMetaInfo struct {
 	CourierResponse
-	Rating   int `json:"rating"`
-	Earnings int `json:"earnings"`
+	Rating     int     `json:"rating"`
+	Earnings   int     `json:"earnings"`
+	OnTimeRate float64 `json:"on_time_rate"`
 }
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+)
+
+// Sentinel errors returned by the repositories and use cases. The HTTP
+// layer maps them to response status codes with errors.Is.
+var (
+	ErrNotFound            = errors.New("not found")
+	ErrConflict            = errors.New("conflict")
+	ErrValidation          = errors.New("validation failed")
+	ErrForbiddenTransition = errors.New("forbidden transition")
+)
+
+// DomainError attaches a message that is safe to show to API clients to
+// one of the sentinel errors.
+type DomainError struct {
+	Kind    error
+	Message string
+	Details []string
+}
+
+func NewDomainError(kind error, message string) *DomainError {
+	return &DomainError{
+		Kind:    kind,
+		Message: message,
+	}
+}
+
+// WithDetails adds the reasons of the error, e.g. every violated limit.
+func (e *DomainError) WithDetails(details ...string) *DomainError {
+	e.Details = append(e.Details, details...)
+	return e
+}
+
+func (e *DomainError) Error() string {
+	if len(e.Details) == 0 {
+		return e.Message
+	}
+
+	return e.Message + ": " + strings.Join(e.Details, "; ")
+}
+
+func (e *DomainError) Unwrap() error {
+	return e.Kind
+}
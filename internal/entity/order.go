@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,6 +10,8 @@ import (
 type Order struct {
 	OrderResponse
 	DistributionDate time.Time `json:"distribution_date"`
+	GroupOrderID     uuid.UUID `json:"group_order_id"`
+	StartedTime      time.Time `json:"started_time"`
 	CreatedAt        time.Time `json:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at"`
 }
@@ -21,6 +24,37 @@ type OrderResponse struct {
 	DeliveryHours []string  `json:"delivery_hours"`
 	Cost          int       `json:"cost"`
 	CompletedTime time.Time `json:"completed_time"`
+	OnTime        bool      `json:"on_time"`
+	// ON_TIME, EARLY or LATE once the order is completed
+	DeliveryStatus string   `json:"delivery_status,omitempty" enums:"ON_TIME,EARLY,LATE"`
+	Address        *Address `json:"address,omitempty"`
+	// The visiting position and the estimated delivery time of the order
+	// in its group, set in the assignments only
+	Sequence int        `json:"sequence,omitempty" example:"1"`
+	ETA      *time.Time `json:"eta,omitempty"`
+}
+
+// The delivery statuses of a completed order, EARLY and LATE orders were
+// completed outside of their delivery hours.
+const (
+	DeliveryOnTime = "ON_TIME"
+	DeliveryEarly  = "EARLY"
+	DeliveryLate   = "LATE"
+)
+
+// Address is the geocoded delivery address of an order.
+type Address struct {
+	Address   string  `json:"address" example:"Lva Tolstogo St, 16"`
+	Latitude  float64 `json:"latitude" example:"55.7339"`
+	Longitude float64 `json:"longitude" example:"37.5871"`
+}
+
+// CompletedOrder is an order of a completion request with the type of its
+// courier. Repeated completions return the stored order with Repeated set.
+type CompletedOrder struct {
+	OrderResponse
+	CourierType string
+	Repeated    bool
 }
 
 type CompleteInfo struct {
@@ -32,9 +66,59 @@ type CompleteInfo struct {
 type OrdersGroup struct {
 	GroupOrderID uuid.UUID       `json:"group_order_id" binding:"required"`
 	Orders       []OrderResponse `json:"orders" binding:"required"`
+	StartedTime  time.Time       `json:"started_time"`
+}
+
+// DeliveryStart returns the start of the earliest "HH:MM-HH:MM" delivery
+// interval, the zero-padded format lets the starts be compared as strings.
+func (o OrderResponse) DeliveryStart() string {
+	earliest := ""
+	for _, hours := range o.DeliveryHours {
+		start, _, _ := strings.Cut(hours, "-")
+		if earliest == "" || start < earliest {
+			earliest = start
+		}
+	}
+
+	return earliest
+}
+
+// DeliveryStart returns the earliest delivery start of the orders of the
+// group.
+func (g OrdersGroup) DeliveryStart() string {
+	earliest := ""
+	for _, order := range g.Orders {
+		if start := order.DeliveryStart(); earliest == "" || start < earliest {
+			earliest = start
+		}
+	}
+
+	return earliest
 }
 
 type CourierAssignment struct {
 	CourierID uuid.UUID     `json:"courier_id" binding:"required"`
 	Orders    []OrdersGroup `json:"orders" binding:"required"`
 }
+
+// AssignmentRun is the outcome of an assignment run: the assignments of the
+// date, the orders the run gave a courier and the number of orders it left
+// without one by region.
+type AssignmentRun struct {
+	Couriers   []*CourierAssignment
+	Assigned   []AssignedOrder
+	Unassigned map[int]int
+}
+
+type AssignedOrder struct {
+	OrderID     uuid.UUID
+	Region      int
+	CourierType string
+}
+
+// Today returns the start of the current UTC day, the date the plans of the
+// day are stored and looked up under. It is UTC whatever the zone of the host:
+// pgx stores the wall clock of the time in the TIMESTAMP columns.
+func Today() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
@@ -0,0 +1,57 @@
+package entity
+
+// Region is an entry of the region catalog the couriers and orders refer
+// to by id. Neighbors are kept symmetric: a region lists every region that
+// lists it.
+type Region struct {
+	RegionID  int      `json:"region_id"`
+	Name      string   `json:"name"`
+	Polygon   *Polygon `json:"polygon,omitempty"`
+	Neighbors []int    `json:"neighbors"`
+}
+
+// Polygon is a GeoJSON polygon. The first ring is the boundary and the rest
+// are holes, every position is [longitude, latitude] and every ring is
+// closed.
+type Polygon struct {
+	Type        string        `json:"type" example:"Polygon"`
+	Coordinates [][][]float64 `json:"coordinates"`
+}
+
+// Contains tells if the point lies inside the boundary of the polygon and
+// outside of its holes. The rings are small enough to treat the coordinates
+// as planar.
+func (p Polygon) Contains(longitude, latitude float64) bool {
+	if len(p.Coordinates) == 0 || !ringContains(p.Coordinates[0], longitude, latitude) {
+		return false
+	}
+
+	for _, hole := range p.Coordinates[1:] {
+		if ringContains(hole, longitude, latitude) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ringContains casts a ray from the point along the longitude axis and
+// counts the edges it crosses.
+func ringContains(ring [][]float64, x, y float64) bool {
+	inside := false
+
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		if len(ring[i]) < 2 || len(ring[j]) < 2 {
+			continue
+		}
+
+		xi, yi := ring[i][0], ring[i][1]
+		xj, yj := ring[j][0], ring[j][1]
+
+		if (yi > y) != (yj > y) && x < (xj-xi)*(y-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
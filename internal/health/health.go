@@ -0,0 +1,90 @@
+// Package health checks the components the service depends on.
+package health
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/almostinf/order_delivery_service/pkg/postgres"
+	"github.com/pressly/goose"
+)
+
+// Checker checks a single component, Name is the key of the component in
+// the readiness report.
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+// PostgresChecker pings the pool.
+type PostgresChecker struct {
+	*postgres.Postgres
+}
+
+func NewPostgresChecker(pg *postgres.Postgres) *PostgresChecker {
+	return &PostgresChecker{pg}
+}
+
+func (c *PostgresChecker) Name() string {
+	return "postgres"
+}
+
+func (c *PostgresChecker) Check(ctx context.Context) error {
+	if err := c.Pool.Ping(ctx); err != nil {
+		return fmt.Errorf("PostgresChecker - Check - c.Pool.Ping: %w", err)
+	}
+
+	return nil
+}
+
+// The latest applied version, versions that were migrated down afterwards
+// are skipped the same way goose does it.
+var _getMigrationVersion = `
+	SELECT COALESCE(MAX(version_id), 0)
+	FROM (
+		SELECT DISTINCT ON (version_id) version_id, is_applied
+		FROM %s
+		ORDER BY version_id, id DESC
+	) AS versions
+	WHERE is_applied;
+`
+
+// MigrationChecker checks that the database is migrated at least to the
+// version the binary expects. A newer database is fine: during a rolling
+// deploy the old replicas keep serving after the new ones migrated it.
+type MigrationChecker struct {
+	*postgres.Postgres
+
+	expected int64
+}
+
+func NewMigrationChecker(pg *postgres.Postgres, expected int64) *MigrationChecker {
+	return &MigrationChecker{Postgres: pg, expected: expected}
+}
+
+func (c *MigrationChecker) Name() string {
+	return "migrations"
+}
+
+func (c *MigrationChecker) Check(ctx context.Context) error {
+	var version int64
+
+	err := c.Pool.QueryRow(ctx, fmt.Sprintf(_getMigrationVersion, goose.TableName())).Scan(&version)
+	if err != nil {
+		return fmt.Errorf("MigrationChecker - Check - c.Pool.QueryRow: %w", err)
+	}
+
+	if err := checkMigrationVersion(version, c.expected); err != nil {
+		return fmt.Errorf("MigrationChecker - Check - %w", err)
+	}
+
+	return nil
+}
+
+func checkMigrationVersion(version, expected int64) error {
+	if version < expected {
+		return fmt.Errorf("database version %d, expected at least %d", version, expected)
+	}
+
+	return nil
+}
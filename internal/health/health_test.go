@@ -0,0 +1,36 @@
+package health
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckMigrationVersion(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name    string
+		version int64
+		isErr   bool
+	}{
+		{name: "behind", version: 20261019170000, isErr: true},
+		{name: "expected", version: 20261019180000},
+		{name: "ahead during a rolling deploy", version: 20261019190000},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := checkMigrationVersion(tc.version, 20261019180000)
+			if tc.isErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/almostinf/order_delivery_service/internal/entity"
+)
+
+type Analytics interface {
+	GetCouriersAnalytics(ctx context.Context, filter entity.CourierAnalyticsFilter) ([]*entity.CourierAnalytics, error)
+	GetOrdersAnalytics(ctx context.Context, filter entity.OrderAnalyticsFilter) ([]*entity.OrderAnalytics, error)
+}
@@ -12,7 +12,10 @@ type Order interface {
 	Get(ctx context.Context, id uuid.UUID) (*entity.OrderResponse, error)
 	GetAll(ctx context.Context, limit, offset int) ([]*entity.OrderResponse, error)
 	Create(ctx context.Context, courier *entity.Order) (*entity.OrderResponse, error)
-	Complete(ctx context.Context, completeInfoReq []entity.CompleteInfo) ([]*entity.OrderResponse, error)
+	Complete(ctx context.Context, completeInfoReq []entity.CompleteInfo) ([]*entity.CompletedOrder, error)
 	SetCourierID(ctx context.Context, orderID uuid.UUID, courierID uuid.UUID) (*entity.OrderResponse, error)
-	Assign(ctx context.Context, date time.Time) ([]*entity.CourierAssignment, error)
+	Unassign(ctx context.Context, orderID uuid.UUID) (*entity.OrderResponse, error)
+	Reassign(ctx context.Context, date time.Time, fromCourierID uuid.UUID, toCourierID uuid.UUID) (*entity.CourierAssignment, error)
+	StartDelivery(ctx context.Context, courierID uuid.UUID, orderIDs []uuid.UUID, startTime time.Time) ([]*entity.OrderResponse, error)
+	Assign(ctx context.Context, date time.Time) (*entity.AssignmentRun, error)
 }
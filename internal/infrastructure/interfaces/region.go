@@ -0,0 +1,15 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/almostinf/order_delivery_service/internal/entity"
+)
+
+type Region interface {
+	GetAll(ctx context.Context) ([]*entity.Region, error)
+	Get(ctx context.Context, id int) (*entity.Region, error)
+	Put(ctx context.Context, region *entity.Region) (*entity.Region, error)
+	Delete(ctx context.Context, id int) error
+	Unknown(ctx context.Context, ids []int) ([]int, error)
+}
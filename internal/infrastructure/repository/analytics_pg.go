@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/almostinf/order_delivery_service/internal/entity"
+	"github.com/almostinf/order_delivery_service/pkg/postgres"
+)
+
+type AnalyticsRepo struct {
+	*postgres.Postgres
+}
+
+func NewAnalyticsRepo(pg *postgres.Postgres) *AnalyticsRepo {
+	return &AnalyticsRepo{pg}
+}
+
+// The coefficients are the same ones GetMetaInfo applies to a single courier.
+// Like the orders analytics, the period includes the start and excludes the end.
+var _getCouriersAnalyticsSchema = `
+	SELECT courier_id, courier_type, regions, working_hours, rating, earnings, completed_orders, on_time_rate
+	FROM (
+		SELECT c.courier_id, c.courier_type, c.regions, c.working_hours,
+			COUNT(o.order_id) / $3 * (CASE c.courier_type WHEN 'FOOT' THEN 3 WHEN 'BIKE' THEN 2 WHEN 'AUTO' THEN 1 ELSE 0 END) AS rating,
+			COALESCE(SUM(o.cost), 0) * (CASE c.courier_type WHEN 'FOOT' THEN 2 WHEN 'BIKE' THEN 3 WHEN 'AUTO' THEN 4 ELSE 0 END) AS earnings,
+			COUNT(o.order_id) AS completed_orders,
+			COALESCE(COUNT(o.order_id) FILTER (WHERE o.on_time)::float / NULLIF(COUNT(o.order_id), 0), 0) AS on_time_rate
+		FROM couriers c
+		LEFT JOIN orders o ON o.courier_id = c.courier_id
+			AND o.completed_time >= $1 AND o.completed_time < $2
+		WHERE ($4::int IS NULL OR $4 = ANY(c.regions))
+			AND ($5::text = '' OR c.courier_type = $5)
+		GROUP BY c.courier_id
+	) AS analytics
+	ORDER BY %s DESC, courier_id
+	LIMIT $6 OFFSET $7;
+`
+
+var _couriersAnalyticsSortColumns = map[string]string{
+	"rating":           "rating",
+	"earnings":         "earnings",
+	"completed_orders": "completed_orders",
+	"on_time_rate":     "on_time_rate",
+}
+
+func (r *AnalyticsRepo) GetCouriersAnalytics(ctx context.Context, filter entity.CourierAnalyticsFilter) ([]*entity.CourierAnalytics, error) {
+	sortColumn, ok := _couriersAnalyticsSortColumns[filter.SortBy]
+	if !ok {
+		return nil, fmt.Errorf("AnalyticsRepo - GetCouriersAnalytics - %w", entity.NewDomainError(entity.ErrValidation, "unknown sort column: "+filter.SortBy))
+	}
+
+	dur := int(filter.EndDate.Sub(filter.StartDate).Hours())
+
+	rows, err := r.Pool.Query(ctx, fmt.Sprintf(_getCouriersAnalyticsSchema, sortColumn),
+		filter.StartDate, filter.EndDate, dur, filter.Region, filter.CourierType, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("AnalyticsRepo - GetCouriersAnalytics - r.Pool.Query: %w", mapPgError(err))
+	}
+	defer rows.Close()
+
+	couriers := make([]*entity.CourierAnalytics, 0)
+	for rows.Next() {
+		e := &entity.CourierAnalytics{}
+
+		err = rows.Scan(&e.CourierID, &e.CourierType, &e.Regions, &e.WorkingHours, &e.Rating, &e.Earnings, &e.CompletedOrders, &e.OnTimeRate)
+		if err != nil {
+			return nil, fmt.Errorf("AnalyticsRepo - GetCouriersAnalytics - rows.Scan: %w", err)
+		}
+
+		e.Rank = filter.Offset + len(couriers) + 1
+		couriers = append(couriers, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("AnalyticsRepo - GetCouriersAnalytics - rows.Err: %w", err)
+	}
+
+	return couriers, nil
+}
+
+// An order breaches the SLA when it was completed outside of its delivery_hours.
+var _getOrdersAnalyticsSchema = `
+	SELECT date_trunc($3, o.created_at) AS bucket_start, o.regions, COALESCE(c.courier_type::text, '') AS courier_type,
+		COUNT(*) AS created_orders,
+		COUNT(*) FILTER (WHERE o.distribution_date <> '0001-01-01 00:00:00') AS assigned_orders,
+		COUNT(*) FILTER (WHERE o.completed_time <> '0001-01-01 00:00:00') AS completed_orders,
+		COALESCE(AVG(EXTRACT(EPOCH FROM o.completed_time - o.created_at) / 60) FILTER (
+			WHERE o.completed_time <> '0001-01-01 00:00:00'
+		), 0)::float AS avg_completion_minutes,
+		COUNT(*) FILTER (WHERE o.completed_time <> '0001-01-01 00:00:00' AND NOT o.on_time) AS sla_breaches,
+		COUNT(*) FILTER (WHERE o.delivery_status = 'EARLY') AS early_deliveries,
+		COUNT(*) FILTER (WHERE o.delivery_status = 'LATE') AS late_deliveries
+	FROM orders o
+	LEFT JOIN couriers c ON c.courier_id = o.courier_id
+	WHERE o.created_at >= $1 AND o.created_at < $2
+		AND ($4::int IS NULL OR o.regions = $4)
+		AND ($5::text = '' OR c.courier_type = $5)
+	GROUP BY bucket_start, o.regions, c.courier_type
+	ORDER BY bucket_start, o.regions, courier_type;
+`
+
+func (r *AnalyticsRepo) GetOrdersAnalytics(ctx context.Context, filter entity.OrderAnalyticsFilter) ([]*entity.OrderAnalytics, error) {
+	rows, err := r.Pool.Query(ctx, _getOrdersAnalyticsSchema, filter.StartDate, filter.EndDate, filter.Bucket, filter.Region, filter.CourierType)
+	if err != nil {
+		return nil, fmt.Errorf("AnalyticsRepo - GetOrdersAnalytics - r.Pool.Query: %w", mapPgError(err))
+	}
+	defer rows.Close()
+
+	orders := make([]*entity.OrderAnalytics, 0)
+	for rows.Next() {
+		e := &entity.OrderAnalytics{}
+
+		err = rows.Scan(&e.BucketStart, &e.Region, &e.CourierType, &e.CreatedOrders, &e.AssignedOrders, &e.CompletedOrders, &e.AvgCompletionMinutes, &e.SLABreaches, &e.EarlyDeliveries, &e.LateDeliveries)
+		if err != nil {
+			return nil, fmt.Errorf("AnalyticsRepo - GetOrdersAnalytics - rows.Scan: %w", err)
+		}
+
+		orders = append(orders, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("AnalyticsRepo - GetOrdersAnalytics - rows.Err: %w", err)
+	}
+
+	return orders, nil
+}
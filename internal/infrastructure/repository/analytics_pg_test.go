@@ -0,0 +1,161 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/almostinf/order_delivery_service/internal/entity"
+	"github.com/almostinf/order_delivery_service/internal/infrastructure/repository"
+	"github.com/stretchr/testify/require"
+)
+
+// The region only exists in this test, so the rows of the other tests don't
+// get into the results.
+const _analyticsRegion = 900001
+
+func TestCouriersAnalyticsUnknownSortColumn(t *testing.T) {
+	t.Parallel()
+
+	// The column is checked before the query is built
+	repo := repository.NewAnalyticsRepo(nil)
+
+	_, err := repo.GetCouriersAnalytics(context.Background(), entity.CourierAnalyticsFilter{SortBy: "courier_id; DROP TABLE couriers"})
+	require.ErrorIs(t, err, entity.ErrValidation)
+}
+
+func TestCouriersAnalytics(t *testing.T) {
+	pg := testPostgres(t)
+	repo := repository.NewAnalyticsRepo(pg)
+
+	start := time.Date(2002, time.February, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 1)
+
+	// rating = completed / hours * (3 on foot, 1 by car), earnings = cost * (2 on foot, 4 by car)
+	foot := insertCourier(t, pg, "FOOT", _analyticsRegion)
+	for i := 0; i < 48; i++ {
+		insertCompletedOrder(t, pg, foot, _analyticsRegion, 100, start, start.Add(12*time.Hour))
+	}
+
+	auto := insertCourier(t, pg, "AUTO", _analyticsRegion)
+	for i := 0; i < 72; i++ {
+		insertCompletedOrder(t, pg, auto, _analyticsRegion, 100, start, start.Add(12*time.Hour))
+	}
+
+	region := _analyticsRegion
+	filter := entity.CourierAnalyticsFilter{StartDate: start, EndDate: end, Region: &region, Limit: 10}
+
+	filter.SortBy = "rating"
+	byRating, err := repo.GetCouriersAnalytics(context.Background(), filter)
+	require.NoError(t, err)
+	require.Len(t, byRating, 2)
+
+	require.Equal(t, foot, byRating[0].CourierID)
+	require.Equal(t, 1, byRating[0].Rank)
+	require.Equal(t, 6, byRating[0].Rating)
+	require.Equal(t, 9600, byRating[0].Earnings)
+	require.Equal(t, 48, byRating[0].CompletedOrders)
+	require.Equal(t, 1.0, byRating[0].OnTimeRate)
+
+	require.Equal(t, auto, byRating[1].CourierID)
+	require.Equal(t, 3, byRating[1].Rating)
+	require.Equal(t, 28800, byRating[1].Earnings)
+
+	filter.SortBy = "earnings"
+	byEarnings, err := repo.GetCouriersAnalytics(context.Background(), filter)
+	require.NoError(t, err)
+	require.Len(t, byEarnings, 2)
+	require.Equal(t, auto, byEarnings[0].CourierID)
+
+	filter.Limit, filter.Offset = 1, 1
+	page, err := repo.GetCouriersAnalytics(context.Background(), filter)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, foot, page[0].CourierID)
+	require.Equal(t, 2, page[0].Rank)
+}
+
+func TestCouriersAnalyticsPeriodBounds(t *testing.T) {
+	pg := testPostgres(t)
+	repo := repository.NewAnalyticsRepo(pg)
+
+	start := time.Date(2002, time.February, 10, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 1)
+
+	courierID := insertCourier(t, pg, "BIKE", _analyticsRegion+1)
+	insertCompletedOrder(t, pg, courierID, _analyticsRegion+1, 100, start, start)
+	insertCompletedOrder(t, pg, courierID, _analyticsRegion+1, 100, start, end)
+
+	region := _analyticsRegion + 1
+	couriers, err := repo.GetCouriersAnalytics(context.Background(), entity.CourierAnalyticsFilter{
+		StartDate: start, EndDate: end, Region: &region, SortBy: "completed_orders", Limit: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, couriers, 1)
+
+	// the order completed at the end date belongs to the next period
+	require.Equal(t, 1, couriers[0].CompletedOrders)
+}
+
+// GetMetaInfo counts the period the same way as the analytics.
+func TestCourierMetaInfoPeriodBounds(t *testing.T) {
+	pg := testPostgres(t)
+
+	start := time.Date(2002, time.February, 11, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 1)
+
+	courierID := insertCourier(t, pg, "BIKE", _analyticsRegion+3)
+	insertCompletedOrder(t, pg, courierID, _analyticsRegion+3, 100, start, start)
+	insertCompletedOrder(t, pg, courierID, _analyticsRegion+3, 100, start, end)
+
+	metaInfo, err := repository.NewCourierRepo(pg).GetMetaInfo(context.Background(), courierID, start, end)
+	require.NoError(t, err)
+
+	// the order completed at the end date belongs to the next period
+	require.Equal(t, 3*100, metaInfo.Earnings)
+}
+
+func TestOrdersAnalytics(t *testing.T) {
+	pg := testPostgres(t)
+	repo := repository.NewAnalyticsRepo(pg)
+
+	monday := time.Date(2002, time.March, 4, 0, 0, 0, 0, time.UTC)
+	end := monday.AddDate(0, 0, 7)
+
+	courierID := insertCourier(t, pg, "AUTO", _analyticsRegion+2)
+	for _, createdAt := range []time.Time{monday, monday.Add(10 * time.Hour), monday.AddDate(0, 0, 1).Add(10 * time.Hour), end} {
+		insertCompletedOrder(t, pg, courierID, _analyticsRegion+2, 100, createdAt, createdAt.Add(time.Hour))
+	}
+
+	region := _analyticsRegion + 2
+
+	testcases := []struct {
+		bucket  string
+		starts  []time.Time
+		created []int
+	}{
+		{bucket: "day", starts: []time.Time{monday, monday.AddDate(0, 0, 1)}, created: []int{2, 1}},
+		{bucket: "week", starts: []time.Time{monday}, created: []int{3}},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+
+		t.Run(tc.bucket, func(t *testing.T) {
+			orders, err := repo.GetOrdersAnalytics(context.Background(), entity.OrderAnalyticsFilter{
+				StartDate: monday, EndDate: end, Bucket: tc.bucket, Region: &region,
+			})
+			require.NoError(t, err)
+			require.Len(t, orders, len(tc.starts))
+
+			for i, bucket := range orders {
+				require.True(t, tc.starts[i].Equal(bucket.BucketStart), "bucket %d starts at %s", i, bucket.BucketStart)
+				require.Equal(t, "AUTO", bucket.CourierType)
+				require.Equal(t, tc.created[i], bucket.CreatedOrders)
+				require.Equal(t, tc.created[i], bucket.CompletedOrders)
+				require.Equal(t, 60.0, bucket.AvgCompletionMinutes)
+				require.Zero(t, bucket.SLABreaches)
+			}
+		})
+	}
+}
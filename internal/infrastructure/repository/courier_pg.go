@@ -1,12 +1,14 @@
 package repository
 
 import (
+	"bytes"
 	"context"
 	"fmt"
-	"log"
+	"sort"
 	"time"
 
 	"github.com/almostinf/order_delivery_service/internal/entity"
+	"github.com/almostinf/order_delivery_service/pkg/logger"
 	"github.com/almostinf/order_delivery_service/pkg/postgres"
 	"github.com/google/uuid"
 )
@@ -29,7 +31,7 @@ func (r *CourierRepo) GetAll(ctx context.Context, limit, offset int) ([]*entity.
 
 	rows, err := r.Pool.Query(ctx, _getAllSchema)
 	if err != nil {
-		return nil, fmt.Errorf("CourierRepo - GetAll - r.Pool.Query: %w", err)
+		return nil, fmt.Errorf("CourierRepo - GetAll - r.Pool.Query: %w", mapPgError(err))
 	}
 	defer rows.Close()
 
@@ -58,7 +60,7 @@ func (r *CourierRepo) Get(ctx context.Context, id uuid.UUID) (*entity.CourierRes
 
 	rows, err := r.Pool.Query(ctx, _getSchema, id)
 	if err != nil {
-		return nil, fmt.Errorf("CourierRepo - Get - r.Pool.Query: %w", err)
+		return nil, fmt.Errorf("CourierRepo - Get - r.Pool.Query: %w", mapPgError(err))
 	}
 	defer rows.Close()
 
@@ -68,7 +70,7 @@ func (r *CourierRepo) Get(ctx context.Context, id uuid.UUID) (*entity.CourierRes
 			return nil, fmt.Errorf("CourierRepo - Get - rows.Scan: %w", err)
 		}
 	} else {
-		return nil, fmt.Errorf("CourierRepo - Get - no rows found")
+		return nil, fmt.Errorf("CourierRepo - Get - %w", entity.NewDomainError(entity.ErrNotFound, "courier not found"))
 	}
 
 	return &courier, nil
@@ -88,22 +90,40 @@ func (r *CourierRepo) Create(ctx context.Context, courier *entity.Courier) (*ent
 		WorkingHours: courier.WorkingHours,
 	}
 
-	err := r.Pool.QueryRow(ctx, _createSchema, courier.CourierID, courier.CourierType, courier.Regions, courier.WorkingHours, courier.CreatedAt, courier.UpdatedAt).Scan(&courierRes.CourierID)
+	tx, err := r.Pool.Begin(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("CourierRepo - Create - r.Pool.QueryRow: %w", err)
+		return nil, fmt.Errorf("CourierRepo - Create - r.Pool.Begin: %w", mapPgError(err))
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	// The regions stay locked until the courier is saved, so a concurrent
+	// delete can't leave the courier with a missing region
+	unknown, err := lockRegions(ctx, tx, courier.Regions)
+	if err != nil {
+		return nil, fmt.Errorf("CourierRepo - Create - lockRegions: %w", err)
+	}
+
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("CourierRepo - Create - %w", unknownRegionsError("unknown regions", unknown))
+	}
+
+	err = tx.QueryRow(ctx, _createSchema, courier.CourierID, courier.CourierType, courier.Regions, courier.WorkingHours, courier.CreatedAt, courier.UpdatedAt).Scan(&courierRes.CourierID)
+	if err != nil {
+		return nil, fmt.Errorf("CourierRepo - Create - tx.QueryRow: %w", mapPgError(err))
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("CourierRepo - Create - tx.Commit: %w", mapPgError(err))
 	}
 
 	return courierRes, nil
 }
 
-var _checkIfCourierExists = `
-	SELECT EXISTS(SELECT 1 FROM couriers WHERE courier_id = $1)
-`
-
+// Like the analytics, the period includes the start and excludes the end.
 var _getTotalCostOfOrdersBetweenDuration = `
-	SELECT cost FROM orders
+	SELECT cost, on_time FROM orders
 	WHERE courier_id = $1
-	AND completed_time BETWEEN $2 AND $3
+	AND completed_time >= $2 AND completed_time < $3
 `
 
 func getTotalCost(costs []int) int {
@@ -128,19 +148,24 @@ func (r *CourierRepo) GetMetaInfo(ctx context.Context, courierID uuid.UUID, star
 
 	rows, err := r.Pool.Query(ctx, _getTotalCostOfOrdersBetweenDuration, courierID, startDate, endDate)
 	if err != nil {
-		return nil, fmt.Errorf("CourierRepo - GetMetaInfo - r.Pool.Query(_getTotalCostOfOrdersBetweenDuration): %w", err)
+		return nil, fmt.Errorf("CourierRepo - GetMetaInfo - r.Pool.Query(_getTotalCostOfOrdersBetweenDuration): %w", mapPgError(err))
 	}
 	defer rows.Close()
 
 	costs := make([]int, 0)
+	onTimeCount := 0
 	for rows.Next() {
 		var cost int
-		err = rows.Scan(&cost)
+		var onTime bool
+		err = rows.Scan(&cost, &onTime)
 		if err != nil {
 			return nil, fmt.Errorf("CourierRepo - GetMetaInfo - rows.Scan: %w", err)
 		}
 
 		costs = append(costs, cost)
+		if onTime {
+			onTimeCount++
+		}
 	}
 
 	var coefCost, coefRating int
@@ -159,136 +184,187 @@ func (r *CourierRepo) GetMetaInfo(ctx context.Context, courierID uuid.UUID, star
 	courierMetaInfo.Earnings = coefCost * getTotalCost(costs)
 
 	dur := int(endDate.Sub(startDate).Hours())
-	log.Println(dur, coefRating, len(costs))
+	logger.FromContext(ctx).With("courier_id", courierID).With("hours", dur).With("completed_orders", len(costs)).
+		Debug("CourierRepo - GetMetaInfo - calculating rating")
 
 	courierMetaInfo.Rating = len(costs) / dur * coefRating
 
+	if len(costs) > 0 {
+		courierMetaInfo.OnTimeRate = float64(onTimeCount) / float64(len(costs))
+	}
+
 	return &courierMetaInfo, nil
 }
 
-var _getAllOrdersWithGivenDistributionDate = `
-	SELECT order_id, weight, regions, delivery_hours, cost, completed_time
-	FROM orders
-	WHERE distribution_date = $1;
+// _getDistributedOrdersWithCouriers loads the orders of the date together
+// with the type of their couriers in one round trip. A nil courier_id
+// argument selects the orders of every courier.
+var _getDistributedOrdersWithCouriers = `
+	SELECT o.order_id, o.courier_id, o.weight, o.regions, o.delivery_hours, o.cost, o.completed_time, o.on_time, o.started_time, o.address, o.latitude, o.longitude, o.distribution_date, o.group_order_id, c.courier_type
+	FROM orders o
+	JOIN couriers c ON c.courier_id = o.courier_id
+	WHERE o.distribution_date = $1
+	AND ($2::uuid IS NULL OR o.courier_id = $2)
+	ORDER BY o.weight DESC, o.order_id;
 `
 
-func (r *CourierRepo) getDistributedOrders(ctx context.Context, date time.Time) ([]*entity.OrderResponse, error) {
-	var orders []*entity.OrderResponse
+// distributedOrder is an order of the date with the type of its courier.
+type distributedOrder struct {
+	entity.Order
+	courierType string
+}
+
+func (r *CourierRepo) getDistributedOrders(ctx context.Context, date time.Time, courierID *uuid.UUID) ([]*distributedOrder, error) {
+	var orders []*distributedOrder
 
-	rows, err := r.Pool.Query(ctx, _getAllOrdersWithGivenDistributionDate, date)
+	rows, err := r.Pool.Query(ctx, _getDistributedOrdersWithCouriers, date, courierID)
 	if err != nil {
-		return nil, fmt.Errorf("CourierRepo - getDistributedOrders - r.Pool.Query: %w", err)
+		return nil, fmt.Errorf("CourierRepo - getDistributedOrders - r.Pool.Query: %w", mapPgError(err))
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		var order entity.OrderResponse
-		err := rows.Scan(&order.OrderID, &order.Weight, &order.Regions, &order.DeliveryHours, &order.Cost, &order.CompletedTime)
+		var order distributedOrder
+		var address addressColumns
+		err := rows.Scan(&order.OrderID, &order.CourierID, &order.Weight, &order.Regions, &order.DeliveryHours, &order.Cost, &order.CompletedTime, &order.OnTime, &order.StartedTime, &address.address, &address.latitude, &address.longitude, &order.DistributionDate, &order.GroupOrderID, &order.courierType)
 		if err != nil {
 			return nil, fmt.Errorf("CourierRepo - getDistributedOrders - rows.Scan: %w", err)
 		}
+		order.Address = address.entity()
 		orders = append(orders, &order)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("CourierRepo - getDistributedOrders - rows.Err: %w", mapPgError(err))
+	}
+
 	return orders, nil
 }
 
-func newOrderGroup(assignments map[uuid.UUID]map[int][]entity.OrdersGroup, courierID uuid.UUID, region int, order *entity.OrderResponse) {
-	ordersGroups := assignments[courierID][order.Regions]
+// setOrderInAssignments puts the order into its stored group.
+func setOrderInAssignments(assignments map[uuid.UUID]map[int][]entity.OrdersGroup, order *entity.Order) {
+	if _, ok := assignments[order.CourierID]; !ok {
+		assignments[order.CourierID] = make(map[int][]entity.OrdersGroup)
+	}
 
-	newOrderGroup := entity.OrdersGroup{
-		GroupOrderID: uuid.New(),
-		Orders:       make([]entity.OrderResponse, 0),
+	groupID := storedGroupOrderID(order)
+	ordersGroup := assignments[order.CourierID][order.Regions]
+
+	i := groupIndex(ordersGroup, groupID)
+	if i < 0 {
+		ordersGroup = append(ordersGroup, entity.OrdersGroup{GroupOrderID: groupID, Orders: make([]entity.OrderResponse, 0)})
+		i = len(ordersGroup) - 1
 	}
 
-	newOrderGroup.Orders = append(newOrderGroup.Orders, *order)
-	ordersGroups = append(ordersGroups, newOrderGroup)
+	ordersGroup[i].Orders = append(ordersGroup[i].Orders, order.OrderResponse)
+	setGroupStartedTime(&ordersGroup[i], order.StartedTime)
 
-	assignments[courierID][order.Regions] = ordersGroups
+	assignments[order.CourierID][order.Regions] = ordersGroup
 }
 
-func (r *CourierRepo) setOrderInAssignments(ctx context.Context, assignments map[uuid.UUID]map[int][]entity.OrdersGroup, courierID uuid.UUID, order *entity.OrderResponse) error {
-	courier, err := r.Get(ctx, courierID)
-	if err != nil {
-		return err
+// setGroupStartedTime keeps the earliest start of the orders of the group,
+// the route of a started group runs from it.
+func setGroupStartedTime(group *entity.OrdersGroup, startedTime time.Time) {
+	if group.StartedTime.IsZero() || (!startedTime.IsZero() && startedTime.Before(group.StartedTime)) {
+		group.StartedTime = startedTime
 	}
+}
 
-	var maxCount int
-	switch courier.CourierType {
-	case "FOOT":
-		maxCount = 2
-	case "BIKE":
-		maxCount = 4
-	case "AUTO":
-		maxCount = 7
+// courierAssignmentsFromGroups flattens the groups sorted by courier, region
+// and delivery window, so the output is the same on every call for the date.
+// The orders of a group come in the visiting order of the courier type.
+func courierAssignmentsFromGroups(assignments map[uuid.UUID]map[int][]entity.OrdersGroup, courierTypes map[uuid.UUID]string, date time.Time) []*entity.CourierAssignment {
+	courierIDs := make([]uuid.UUID, 0, len(assignments))
+	for courierID := range assignments {
+		courierIDs = append(courierIDs, courierID)
 	}
+	sort.Slice(courierIDs, func(i, j int) bool {
+		return bytes.Compare(courierIDs[i][:], courierIDs[j][:]) < 0
+	})
+
+	couriersAssignment := make([]*entity.CourierAssignment, 0, len(assignments))
+
+	for _, courierID := range courierIDs {
+		regions := assignments[courierID]
+
+		assignment := entity.CourierAssignment{
+			CourierID: courierID,
+			Orders:    make([]entity.OrdersGroup, 0),
+		}
+
+		regionIDs := make([]int, 0, len(regions))
+		for region := range regions {
+			regionIDs = append(regionIDs, region)
+		}
+		sort.Ints(regionIDs)
+
+		limits := getCourierLimits(courierTypes[courierID])
+
+		for _, region := range regionIDs {
+			groups := regions[region]
+			sortOrdersGroups(groups)
 
-	if reg, ok := assignments[courierID]; ok {
-		for region, ordersGroup := range reg {
-			if region == order.Regions {
-				for _, orderGroup := range ordersGroup {
-					if len(orderGroup.Orders) < maxCount {
-						orderGroup.Orders = append(orderGroup.Orders, *order)
-						assignments[courierID][order.Regions] = ordersGroup
-						return nil
-					}
-				}
-
-				newOrderGroup(assignments, courierID, order.Regions, order)
+			for _, orderGroup := range groups {
+				sequenceGroup(&orderGroup, limits, date)
+				assignment.Orders = append(assignment.Orders, orderGroup)
 			}
 		}
-	} else {
-		assignments[courierID] = make(map[int][]entity.OrdersGroup)
-		assignments[courierID][order.Regions] = make([]entity.OrdersGroup, 0)
 
-		newOrderGroup(assignments, courierID, order.Regions, order)
+		couriersAssignment = append(couriersAssignment, &assignment)
 	}
 
-	return nil
+	return couriersAssignment
 }
 
-func (r *CourierRepo) GetAssignments(ctx context.Context, date time.Time, courierID uuid.UUID, isAllCouriers bool) ([]*entity.CourierAssignment, error) {
-	orderRepo := NewOrderRepo(r.Postgres)
-	couriersAssignment := make([]*entity.CourierAssignment, 0)
-	assignments := make(map[uuid.UUID]map[int][]entity.OrdersGroup) // mapping: courier_id -> region -> slice of orders group
-	distributedOrders, err := r.getDistributedOrders(ctx, date)
-	log.Println("Len of distributed orders: ", len(distributedOrders))
-
-	if err != nil {
-		return nil, err
+// sortOrdersGroups sorts the orders of every group and then the groups by
+// the delivery window. Ties are broken by the stored group ids, so the groups
+// keep their places when orders are added to or removed from them.
+func sortOrdersGroups(groups []entity.OrdersGroup) {
+	for _, group := range groups {
+		sort.Slice(group.Orders, func(i, j int) bool {
+			return orderLess(group.Orders[i], group.Orders[j])
+		})
 	}
 
-	for _, distributedOrder := range distributedOrders {
-		order, err := orderRepo.Get(ctx, distributedOrder.OrderID)
-		if err != nil {
-			return nil, err
+	sort.Slice(groups, func(i, j int) bool {
+		startI, startJ := groups[i].DeliveryStart(), groups[j].DeliveryStart()
+		if startI != startJ {
+			return startI < startJ
 		}
 
-		if isAllCouriers {
-			if err := r.setOrderInAssignments(ctx, assignments, order.CourierID, distributedOrder); err != nil {
-				return nil, err
-			}
-		} else {
-			if order.CourierID == courierID {
-				if err := r.setOrderInAssignments(ctx, assignments, courierID, distributedOrder); err != nil {
-					return nil, err
-				}
-			}
-		}
+		return bytes.Compare(groups[i].GroupOrderID[:], groups[j].GroupOrderID[:]) < 0
+	})
+}
+
+func orderLess(a, b entity.OrderResponse) bool {
+	if startA, startB := a.DeliveryStart(), b.DeliveryStart(); startA != startB {
+		return startA < startB
 	}
 
-	for courierID, regions := range assignments {
-		assignment := entity.CourierAssignment{
-			CourierID: courierID,
-			Orders:    make([]entity.OrdersGroup, 0),
-		}
+	return bytes.Compare(a.OrderID[:], b.OrderID[:]) < 0
+}
 
-		for _, orderGroup := range regions {
-			assignment.Orders = append(assignment.Orders, orderGroup...)
-		}
+func (r *CourierRepo) GetAssignments(ctx context.Context, date time.Time, courierID uuid.UUID, isAllCouriers bool) ([]*entity.CourierAssignment, error) {
+	assignments := make(map[uuid.UUID]map[int][]entity.OrdersGroup) // mapping: courier_id -> region -> slice of orders group
 
-		couriersAssignment = append(couriersAssignment, &assignment)
+	var courierFilter *uuid.UUID
+	if !isAllCouriers {
+		courierFilter = &courierID
+	}
+
+	distributedOrders, err := r.getDistributedOrders(ctx, date, courierFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).With("date", date.Format("2006-01-02")).With("orders", len(distributedOrders)).
+		Debug("CourierRepo - GetAssignments - loaded distributed orders")
+
+	courierTypes := make(map[uuid.UUID]string)
+	for _, order := range distributedOrders {
+		setOrderInAssignments(assignments, &order.Order)
+		courierTypes[order.CourierID] = order.courierType
 	}
 
-	return couriersAssignment, nil
+	return courierAssignmentsFromGroups(assignments, courierTypes, date), nil
 }
@@ -0,0 +1,223 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/almostinf/order_delivery_service/internal/app"
+	"github.com/almostinf/order_delivery_service/internal/entity"
+	"github.com/almostinf/order_delivery_service/internal/infrastructure/repository"
+	"github.com/almostinf/order_delivery_service/pkg/logger"
+	"github.com/almostinf/order_delivery_service/pkg/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// queryCounter counts the round trips of the pool.
+type queryCounter struct {
+	queries atomic.Int64
+}
+
+func (c *queryCounter) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	c.queries.Add(1)
+	return ctx
+}
+
+func (c *queryCounter) TraceQueryEnd(context.Context, *pgx.Conn, pgx.TraceQueryEndData) {}
+
+// insertDistributedOrders inserts the couriers and the orders distributed to
+// them on the date and returns a cleanup removing them.
+func insertDistributedOrders(b *testing.B, pg *postgres.Postgres, date time.Time, couriers, ordersPerCourier int) func() {
+	b.Helper()
+
+	ctx := context.Background()
+	now := time.Now()
+
+	var courierIDs []uuid.UUID
+	for i := 0; i < couriers; i++ {
+		courierID := uuid.New()
+		courierIDs = append(courierIDs, courierID)
+
+		_, err := pg.Pool.Exec(ctx, `
+			INSERT INTO couriers (courier_id, courier_type, regions, working_hours, created_at, updated_at)
+			VALUES ($1, 'AUTO', $2, $3, $4, $4)`,
+			courierID, []int{1, 2, 3}, []string{"08:00-20:00"}, now)
+		if err != nil {
+			b.Fatalf("insert courier: %v", err)
+		}
+
+		for j := 0; j < ordersPerCourier; j++ {
+			_, err := pg.Pool.Exec(ctx, `
+				INSERT INTO orders (order_id, courier_id, weight, regions, delivery_hours, cost, completed_time, distribution_date, created_at, updated_at)
+				VALUES ($1, $2, $3, $4, $5, 100, '0001-01-01', $6, $7, $7)`,
+				uuid.New(), courierID, float32(j%10+1), j%3+1, []string{"10:00-12:00"}, date, now)
+			if err != nil {
+				b.Fatalf("insert order: %v", err)
+			}
+		}
+	}
+
+	return func() {
+		_, _ = pg.Pool.Exec(ctx, `DELETE FROM orders WHERE courier_id = ANY($1)`, courierIDs)
+		_, _ = pg.Pool.Exec(ctx, `DELETE FROM couriers WHERE courier_id = ANY($1)`, courierIDs)
+	}
+}
+
+// groupIDs lists the groups of the assignment with the ids of their orders in
+// the visiting order.
+func groupIDs(assignment *entity.CourierAssignment) map[uuid.UUID][]uuid.UUID {
+	groups := make(map[uuid.UUID][]uuid.UUID, len(assignment.Orders))
+	for _, group := range assignment.Orders {
+		for _, order := range group.Orders {
+			groups[group.GroupOrderID] = append(groups[group.GroupOrderID], order.OrderID)
+		}
+	}
+
+	return groups
+}
+
+func TestGetAssignmentsMatchesAssign(t *testing.T) {
+	t.Parallel()
+
+	const region = 9_047
+
+	pg := testPostgres(t)
+	ctx := context.Background()
+	date := time.Date(2003, time.April, 1, 0, 0, 0, 0, time.UTC)
+
+	courierID := insertCourier(t, pg, "FOOT", region)
+	for _, hours := range []string{"09:00-10:00", "09:00-10:00", "09:30-10:30", "12:00-13:00", "12:00-13:00", "16:00-17:00"} {
+		_, err := pg.Pool.Exec(ctx, `
+			INSERT INTO orders (order_id, weight, regions, delivery_hours, cost, completed_time, distribution_date, created_at, updated_at)
+			VALUES ($1, 1, $2, $3, 100, '0001-01-01', '0001-01-01', now(), now())`,
+			uuid.New(), region, []string{hours})
+		require.NoError(t, err)
+	}
+
+	run, err := repository.NewOrderRepo(pg).Assign(ctx, date)
+	require.NoError(t, err)
+
+	var assigned *entity.CourierAssignment
+	for _, assignment := range run.Couriers {
+		if assignment.CourierID == courierID {
+			assigned = assignment
+		}
+	}
+	require.NotNil(t, assigned)
+
+	stored, err := repository.NewCourierRepo(pg).GetAssignments(ctx, date, courierID, false)
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+
+	// The regrouped stored orders are the groups of the run in the same order
+	require.Equal(t, groupIDs(assigned), groupIDs(stored[0]))
+	require.Len(t, stored[0].Orders, len(assigned.Orders))
+	for i := range assigned.Orders {
+		require.Equal(t, assigned.Orders[i].GroupOrderID, stored[0].Orders[i].GroupOrderID)
+	}
+}
+
+// getAssignmentsPerOrder is the baseline of BenchmarkGetAssignments: the
+// round trips GetAssignments made before it joined the couriers, a query for
+// the orders of the date, then the order and its courier for each of them.
+func getAssignmentsPerOrder(ctx context.Context, pg *postgres.Postgres, date time.Time) error {
+	orderRepo := repository.NewOrderRepo(pg)
+	courierRepo := repository.NewCourierRepo(pg)
+
+	rows, err := pg.Pool.Query(ctx, `SELECT order_id FROM orders WHERE distribution_date = $1 ORDER BY weight DESC, order_id`, date)
+	if err != nil {
+		return err
+	}
+
+	orderIDs, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return err
+	}
+
+	for _, orderID := range orderIDs {
+		order, err := orderRepo.Get(ctx, orderID)
+		if err != nil {
+			return err
+		}
+
+		if _, err := courierRepo.Get(ctx, order.CourierID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// BenchmarkGetAssignments needs a Postgres at PG_URL. It compares
+// GetAssignments with the per-order baseline: the queries/op metric stays at
+// one round trip whatever the number of orders on the date, the baseline makes
+// two per order.
+func BenchmarkGetAssignments(b *testing.B) {
+	url := os.Getenv("PG_URL")
+	if url == "" {
+		b.Skip("PG_URL is not set")
+	}
+
+	if err := app.Migrate(logger.New("error"), url, "up"); err != nil {
+		b.Fatalf("migrate: %v", err)
+	}
+
+	counter := &queryCounter{}
+	pg, err := postgres.New(url, postgres.MaxPoolSize(4), postgres.Tracer(counter))
+	if err != nil {
+		b.Fatalf("postgres.New: %v", err)
+	}
+	defer pg.Close()
+
+	repo := repository.NewCourierRepo(pg)
+
+	paths := []struct {
+		name string
+		get  func(ctx context.Context, date time.Time) error
+	}{
+		{
+			name: "joined",
+			get: func(ctx context.Context, date time.Time) error {
+				_, err := repo.GetAssignments(ctx, date, uuid.Nil, true)
+				return err
+			},
+		},
+		{
+			name: "per-order",
+			get: func(ctx context.Context, date time.Time) error {
+				return getAssignmentsPerOrder(ctx, pg, date)
+			},
+		},
+	}
+
+	for i, size := range []struct{ couriers, ordersPerCourier int }{{10, 10}, {50, 20}, {100, 50}} {
+		date := time.Date(2001, time.January, i+1, 0, 0, 0, 0, time.UTC)
+
+		func() {
+			cleanup := insertDistributedOrders(b, pg, date, size.couriers, size.ordersPerCourier)
+			defer cleanup()
+
+			for _, path := range paths {
+				path := path
+
+				b.Run(fmt.Sprintf("%s/orders=%d", path.name, size.couriers*size.ordersPerCourier), func(b *testing.B) {
+					counter.queries.Store(0)
+					b.ResetTimer()
+
+					for n := 0; n < b.N; n++ {
+						if err := path.get(context.Background(), date); err != nil {
+							b.Fatalf("%s: %v", path.name, err)
+						}
+					}
+
+					b.ReportMetric(float64(counter.queries.Load())/float64(b.N), "queries/op")
+				})
+			}
+		}()
+	}
+}
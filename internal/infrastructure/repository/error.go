@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/almostinf/order_delivery_service/internal/entity"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Postgres error codes, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	_pgUniqueViolation       = "23505"
+	_pgForeignKeyViolation   = "23503"
+	_pgCheckViolation        = "23514"
+	_pgInvalidTextRepr       = "22P02"
+	_pgSerializationFailure  = "40001"
+	_pgDeadlockDetected      = "40P01"
+	_pgLockNotAvailableError = "55P03"
+)
+
+// The messages shown to the clients. The details of Postgres name the
+// constraints and the key values, so they only get into the logs.
+var _pgErrorMessages = map[string]string{
+	_pgUniqueViolation:       "the entity already exists",
+	_pgSerializationFailure:  "the entity was changed concurrently, retry the request",
+	_pgDeadlockDetected:      "the entity was changed concurrently, retry the request",
+	_pgLockNotAvailableError: "the entity is locked by another request, retry the request",
+	_pgForeignKeyViolation:   "a referenced entity doesn't exist",
+	_pgCheckViolation:        "a value violates a constraint",
+	_pgInvalidTextRepr:       "a value has an invalid format",
+}
+
+// mapPgError converts pgx and Postgres errors into the entity sentinel
+// errors so the callers don't depend on the driver.
+func mapPgError(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.NewDomainError(entity.ErrNotFound, "entity not found")
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	var kind error
+	switch pgErr.Code {
+	case _pgUniqueViolation, _pgSerializationFailure, _pgDeadlockDetected, _pgLockNotAvailableError:
+		kind = entity.ErrConflict
+	case _pgForeignKeyViolation:
+		kind = entity.ErrNotFound
+	case _pgCheckViolation, _pgInvalidTextRepr:
+		kind = entity.ErrValidation
+	default:
+		return err
+	}
+
+	return fmt.Errorf("%w: %s", entity.NewDomainError(kind, _pgErrorMessages[pgErr.Code]), pgErrorText(pgErr))
+}
+
+func pgErrorText(pgErr *pgconn.PgError) string {
+	if pgErr.Detail == "" {
+		return pgErr.Message
+	}
+
+	return pgErr.Message + " (" + pgErr.Detail + ")"
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/almostinf/order_delivery_service/internal/entity"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapPgError(t *testing.T) {
+	t.Parallel()
+
+	otherErr := errors.New("connection refused")
+
+	testcases := []struct {
+		name    string
+		err     error
+		kind    error
+		message string
+	}{
+		{name: "no rows", err: pgx.ErrNoRows, kind: entity.ErrNotFound, message: "entity not found"},
+		{name: "unique violation", err: &pgconn.PgError{Code: _pgUniqueViolation, Message: "duplicate key", Detail: "Key (order_id)=(1) already exists."}, kind: entity.ErrConflict, message: "the entity already exists"},
+		{name: "foreign key violation", err: &pgconn.PgError{Code: _pgForeignKeyViolation, Message: "violates foreign key constraint \"orders_courier_id_fkey\"", Detail: "Key (courier_id)=(2) is not present."}, kind: entity.ErrNotFound, message: "a referenced entity doesn't exist"},
+		{name: "check violation", err: &pgconn.PgError{Code: _pgCheckViolation, Message: "violates check constraint \"regions_region_id_check\""}, kind: entity.ErrValidation, message: "a value violates a constraint"},
+		{name: "deadlock", err: &pgconn.PgError{Code: _pgDeadlockDetected, Message: "deadlock detected"}, kind: entity.ErrConflict, message: "the entity was changed concurrently, retry the request"},
+		{name: "wrapped lock timeout", err: fmt.Errorf("tx: %w", &pgconn.PgError{Code: _pgLockNotAvailableError, Message: "could not obtain lock"}), kind: entity.ErrConflict, message: "the entity is locked by another request, retry the request"},
+		{name: "other error", err: otherErr},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := mapPgError(tc.err)
+
+			if tc.kind == nil {
+				require.Equal(t, tc.err, err)
+				return
+			}
+
+			require.ErrorIs(t, err, tc.kind)
+
+			var domainErr *entity.DomainError
+			require.ErrorAs(t, err, &domainErr)
+			require.Equal(t, tc.message, domainErr.Message)
+
+			// the details stay in the error text for the logs
+			var pgErr *pgconn.PgError
+			if errors.As(tc.err, &pgErr) {
+				require.Contains(t, err.Error(), pgErr.Message)
+				require.Contains(t, err.Error(), pgErr.Detail)
+			}
+		})
+	}
+}
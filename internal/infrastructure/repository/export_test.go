@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/almostinf/order_delivery_service/internal/entity"
+	"github.com/google/uuid"
+)
+
+// SaveAssignment exposes saveAssignment to the tests of repository_test.
+func (r *OrderRepo) SaveAssignment(ctx context.Context, date time.Time, orderIDs, courierIDs, groupIDs []uuid.UUID, seeded []*entity.Order) error {
+	return r.saveAssignment(ctx, date, orderIDs, courierIDs, groupIDs, seeded)
+}
@@ -1,16 +1,22 @@
 package repository
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
-	"log"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/almostinf/order_delivery_service/internal/entity"
+	"github.com/almostinf/order_delivery_service/internal/tracing"
+	"github.com/almostinf/order_delivery_service/pkg/logger"
 	"github.com/almostinf/order_delivery_service/pkg/postgres"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type OrderRepo struct {
@@ -22,7 +28,7 @@ func NewOrderRepo(pg *postgres.Postgres) *OrderRepo {
 }
 
 var _getAllOrdersSchema = `
-	SELECT order_id, courier_id, weight, regions, delivery_hours, cost, completed_time
+	SELECT order_id, courier_id, weight, regions, delivery_hours, cost, completed_time, on_time, delivery_status, address, latitude, longitude
 	FROM orders;
 `
 
@@ -31,17 +37,19 @@ func (r *OrderRepo) GetAll(ctx context.Context, limit, offset int) ([]*entity.Or
 
 	rows, err := r.Pool.Query(ctx, _getAllOrdersSchema)
 	if err != nil {
-		return nil, fmt.Errorf("OrderRepo - GetAll - r.Pool.Query: %w", err)
+		return nil, fmt.Errorf("OrderRepo - GetAll - r.Pool.Query: %w", mapPgError(err))
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		e := &entity.OrderResponse{}
+		var address addressColumns
 
-		err = rows.Scan(&e.OrderID, &e.CourierID, &e.Weight, &e.Regions, &e.DeliveryHours, &e.Cost, &e.CompletedTime)
+		err = rows.Scan(&e.OrderID, &e.CourierID, &e.Weight, &e.Regions, &e.DeliveryHours, &e.Cost, &e.CompletedTime, &e.OnTime, &e.DeliveryStatus, &address.address, &address.latitude, &address.longitude)
 		if err != nil {
 			return nil, fmt.Errorf("OrderRepo - GetAll - rows.Scan: %w", err)
 		}
+		e.Address = address.entity()
 
 		orders = append(orders, e)
 	}
@@ -50,7 +58,7 @@ func (r *OrderRepo) GetAll(ctx context.Context, limit, offset int) ([]*entity.Or
 }
 
 var _getOrderSchema = `
-	SELECT order_id, courier_id, weight, regions, delivery_hours, cost, completed_time
+	SELECT order_id, courier_id, weight, regions, delivery_hours, cost, completed_time, on_time, delivery_status, address, latitude, longitude
 	FROM orders
 	WHERE order_id = $1;
 `
@@ -60,52 +68,73 @@ func (r *OrderRepo) Get(ctx context.Context, id uuid.UUID) (*entity.OrderRespons
 
 	rows, err := r.Pool.Query(ctx, _getOrderSchema, id)
 	if err != nil {
-		return nil, fmt.Errorf("OrderRepo - Get - r.Pool.Query: %w", err)
+		return nil, fmt.Errorf("OrderRepo - Get - r.Pool.Query: %w", mapPgError(err))
 	}
 	defer rows.Close()
 
 	if rows.Next() {
-		err := rows.Scan(&order.OrderID, &order.CourierID, &order.Weight, &order.Regions, &order.DeliveryHours, &order.Cost, &order.CompletedTime)
+		var address addressColumns
+
+		err := rows.Scan(&order.OrderID, &order.CourierID, &order.Weight, &order.Regions, &order.DeliveryHours, &order.Cost, &order.CompletedTime, &order.OnTime, &order.DeliveryStatus, &address.address, &address.latitude, &address.longitude)
 		if err != nil {
 			return nil, fmt.Errorf("OrderRepo - Get - rows.Scan: %w", err)
 		}
+		order.Address = address.entity()
 	} else {
-		return nil, fmt.Errorf("OrderRepo - Get - no rows found")
+		return nil, fmt.Errorf("OrderRepo - Get - %w", entity.NewDomainError(entity.ErrNotFound, "order not found"))
 	}
 
 	return &order, nil
 }
 
-var _getFullOrder = `
-	SELECT order_id, courier_id, weight, regions, delivery_hours, cost, completed_time, courier_id, distribution_date, created_at, updated_at
+// addressColumns holds the nullable address columns of an order, the orders
+// created with a region only have none of them.
+type addressColumns struct {
+	address   *string
+	latitude  *float64
+	longitude *float64
+}
+
+func newAddressColumns(address *entity.Address) addressColumns {
+	if address == nil {
+		return addressColumns{}
+	}
+
+	return addressColumns{&address.Address, &address.Latitude, &address.Longitude}
+}
+
+func (a addressColumns) entity() *entity.Address {
+	if a.address == nil || a.latitude == nil || a.longitude == nil {
+		return nil
+	}
+
+	return &entity.Address{Address: *a.address, Latitude: *a.latitude, Longitude: *a.longitude}
+}
+
+var _getFullOrderForUpdate = `
+	SELECT order_id, courier_id, weight, regions, delivery_hours, cost, completed_time, on_time, delivery_status, distribution_date, group_order_id, created_at, updated_at
 	FROM orders
-	WHERE order_id = $1;
+	WHERE order_id = $1
+	FOR UPDATE;
 `
 
-func (r *OrderRepo) getFullOrder(ctx context.Context, id uuid.UUID) (*entity.Order, error) {
+func getFullOrderForUpdate(ctx context.Context, q querier, id uuid.UUID) (*entity.Order, error) {
 	var order entity.Order
 
-	rows, err := r.Pool.Query(ctx, _getFullOrder, id)
-	if err != nil {
-		return nil, fmt.Errorf("OrderRepo - getFullOrder - r.Pool.Query: %w", err)
+	err := q.QueryRow(ctx, _getFullOrderForUpdate, id).Scan(&order.OrderID, &order.CourierID, &order.Weight, &order.Regions, &order.DeliveryHours, &order.Cost, &order.CompletedTime, &order.OnTime, &order.DeliveryStatus, &order.DistributionDate, &order.GroupOrderID, &order.CreatedAt, &order.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("getFullOrderForUpdate - %w", entity.NewDomainError(entity.ErrNotFound, "order not found"))
 	}
-	defer rows.Close()
-
-	if rows.Next() {
-		err := rows.Scan(&order.OrderID, &order.CourierID, &order.Weight, &order.Regions, &order.DeliveryHours, &order.Cost, &order.CompletedTime, &order.CourierID, &order.DistributionDate, &order.CreatedAt, &order.UpdatedAt)
-		if err != nil {
-			return nil, fmt.Errorf("OrderRepo - getFullOrder - rows.Scan: %w", err)
-		}
-	} else {
-		return nil, fmt.Errorf("OrderRepo - getFullOrder - no rows found")
+	if err != nil {
+		return nil, fmt.Errorf("getFullOrderForUpdate - q.QueryRow: %w", mapPgError(err))
 	}
 
 	return &order, nil
 }
 
 var _createOrderSchema = `
-	INSERT INTO orders (order_id, weight, regions, delivery_hours, cost, completed_time, distribution_date, created_at, updated_at)
-	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	INSERT INTO orders (order_id, weight, regions, delivery_hours, cost, completed_time, distribution_date, created_at, updated_at, address, latitude, longitude)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 	RETURNING order_id;
 `
 
@@ -117,90 +146,420 @@ func (r *OrderRepo) Create(ctx context.Context, order *entity.Order) (*entity.Or
 		DeliveryHours: order.DeliveryHours,
 		Cost:          order.Cost,
 		CompletedTime: order.CompletedTime,
+		Address:       order.Address,
+	}
+
+	address := newAddressColumns(order.Address)
+
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("OrderRepo - Create - r.Pool.Begin: %w", mapPgError(err))
 	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
 
-	err := r.Pool.QueryRow(ctx, _createOrderSchema, order.OrderID, order.Weight, order.Regions, order.DeliveryHours, order.Cost, order.CompletedTime, time.Time{}, order.CreatedAt, order.UpdatedAt).Scan(&orderRes.OrderID)
+	// The region stays locked until the order is saved, so a concurrent
+	// delete can't leave the order with a missing region
+	unknown, err := lockRegions(ctx, tx, []int{order.Regions})
 	if err != nil {
-		return nil, fmt.Errorf("OrderRepo - Create - r.Pool.QueryRow: %w", err)
+		return nil, fmt.Errorf("OrderRepo - Create - lockRegions: %w", err)
+	}
+
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("OrderRepo - Create - %w", unknownRegionsError("unknown regions", unknown))
+	}
+
+	err = tx.QueryRow(ctx, _createOrderSchema, order.OrderID, order.Weight, order.Regions, order.DeliveryHours, order.Cost, order.CompletedTime, time.Time{}, order.CreatedAt, order.UpdatedAt, address.address, address.latitude, address.longitude).Scan(&orderRes.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("OrderRepo - Create - tx.QueryRow: %w", mapPgError(err))
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("OrderRepo - Create - tx.Commit: %w", mapPgError(err))
 	}
 
 	return orderRes, nil
 }
 
 var _setOrderCompletedTime = `
-	UPDATE orders SET completed_time = $1 WHERE order_id = $2
+	UPDATE orders SET completed_time = $1, on_time = $2, delivery_status = $3 WHERE order_id = $4
 `
 
-func (r *OrderRepo) Complete(ctx context.Context, completeInfoReq []entity.CompleteInfo) ([]*entity.OrderResponse, error) {
-	orders := make([]*entity.OrderResponse, 0)
+// The devices of the couriers may run a bit ahead of the server clock.
+const _completeTimeSkew = time.Minute
+
+func (r *OrderRepo) Complete(ctx context.Context, completeInfoReq []entity.CompleteInfo) ([]*entity.CompletedOrder, error) {
+	l := logger.FromContext(ctx)
+
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("OrderRepo - Complete - r.Pool.Begin: %w", mapPgError(err))
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	courierRepo := NewCourierRepo(r.Postgres)
+	couriers := make(map[uuid.UUID]*entity.CourierResponse) // mapping: courier_id -> courier of the batch
+
+	// The batch is completed as a whole: every order stays locked until the
+	// commit, and any failed order rolls back the ones before it
+	orders := make([]*entity.CompletedOrder, 0)
 	for _, completeInfo := range completeInfoReq {
-		fullOrder, err := r.getFullOrder(ctx, completeInfo.OrderID)
+		fullOrder, err := getFullOrderForUpdate(ctx, tx, completeInfo.OrderID)
 		if err != nil {
-			return nil, fmt.Errorf("OrderRepo - Complete - r.Get: %w", err)
+			return nil, fmt.Errorf("OrderRepo - Complete - %w", err)
 		}
 
-		_, err = r.Pool.Exec(ctx, _checkIfCourierExists, completeInfo.CourierID)
-		if err != nil {
-			return nil, fmt.Errorf("OrderRepo - Complete - r.Pool.Exec(_checkIfCourierExists): %w", err)
+		courier, ok := couriers[completeInfo.CourierID]
+		if !ok {
+			courier, err = courierRepo.Get(ctx, completeInfo.CourierID)
+			if err != nil {
+				return nil, fmt.Errorf("OrderRepo - Complete - courierRepo.Get: %w", err)
+			}
+			couriers[completeInfo.CourierID] = courier
 		}
 
 		if fullOrder.CourierID != completeInfo.CourierID {
-			return nil, fmt.Errorf("OrderRepo - Complete - courierID and courierIDResponse don't match")
+			return nil, fmt.Errorf("OrderRepo - Complete - %w", entity.NewDomainError(entity.ErrForbiddenTransition, "courier_id and the order courier don't match"))
+		}
+
+		// Completing an order again returns the first completion as is
+		if !fullOrder.CompletedTime.IsZero() {
+			orders = append(orders, &entity.CompletedOrder{OrderResponse: fullOrder.OrderResponse, CourierType: courier.CourierType, Repeated: true})
+			continue
+		}
+
+		if completeInfo.CompleteTime.After(time.Now().Add(_completeTimeSkew)) {
+			return nil, fmt.Errorf("OrderRepo - Complete - %w", entity.NewDomainError(entity.ErrValidation, "complete_time is in the future"))
 		}
 
 		if !(fullOrder.DistributionDate.Day() == completeInfo.CompleteTime.Day() && fullOrder.DistributionDate.Month() == completeInfo.CompleteTime.Month() &&
 			fullOrder.DistributionDate.Year() == completeInfo.CompleteTime.Year()) {
-			return nil, fmt.Errorf("OrderRepo - Complete - complete_time and distribution_date don't match")
+			return nil, fmt.Errorf("OrderRepo - Complete - %w", entity.NewDomainError(entity.ErrValidation, "complete_time and distribution_date don't match"))
 		}
 
-		if fullOrder.CompletedTime.IsZero() {
-			fullOrder.CompletedTime = completeInfo.CompleteTime
+		if !inTimeRanges(completeInfo.CompleteTime, courier.WorkingHours) {
+			return nil, fmt.Errorf("OrderRepo - Complete - %w", entity.NewDomainError(entity.ErrValidation, "complete_time is outside of the courier working hours"))
 		}
 
-		_, err = r.Pool.Exec(ctx, _setOrderCompletedTime, completeInfo.CompleteTime, completeInfo.OrderID)
+		fullOrder.CompletedTime = completeInfo.CompleteTime
+		fullOrder.DeliveryStatus = deliveryStatus(completeInfo.CompleteTime, fullOrder.DeliveryHours)
+		fullOrder.OnTime = fullOrder.DeliveryStatus == entity.DeliveryOnTime
+		if !fullOrder.OnTime {
+			l.With("order_id", fullOrder.OrderID).With("delivery_status", fullOrder.DeliveryStatus).Info("OrderRepo - Complete - order was delivered outside of the delivery hours")
+		}
+
+		_, err = tx.Exec(ctx, _setOrderCompletedTime, fullOrder.CompletedTime, fullOrder.OnTime, fullOrder.DeliveryStatus, completeInfo.OrderID)
 		if err != nil {
-			return nil, fmt.Errorf("OrderRepo - Complete - r.Pool.Exec(_setOrderCompletedTime): %w", err)
+			return nil, fmt.Errorf("OrderRepo - Complete - tx.Exec(_setOrderCompletedTime): %w", mapPgError(err))
 		}
 
-		order := &entity.OrderResponse{
-			OrderID:       fullOrder.OrderID,
-			CourierID:     fullOrder.CourierID,
-			Weight:        fullOrder.Weight,
-			Regions:       fullOrder.Regions,
-			DeliveryHours: fullOrder.DeliveryHours,
-			Cost:          fullOrder.Cost,
-			CompletedTime: fullOrder.CompletedTime,
+		order := &entity.CompletedOrder{
+			OrderResponse: entity.OrderResponse{
+				OrderID:        fullOrder.OrderID,
+				CourierID:      fullOrder.CourierID,
+				Weight:         fullOrder.Weight,
+				Regions:        fullOrder.Regions,
+				DeliveryHours:  fullOrder.DeliveryHours,
+				Cost:           fullOrder.Cost,
+				CompletedTime:  fullOrder.CompletedTime,
+				OnTime:         fullOrder.OnTime,
+				DeliveryStatus: fullOrder.DeliveryStatus,
+			},
+			CourierType: courier.CourierType,
 		}
 
 		orders = append(orders, order)
 	}
 
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("OrderRepo - Complete - tx.Commit: %w", mapPgError(err))
+	}
+
 	return orders, nil
 }
 
+// querier is implemented by both *pgxpool.Pool and pgx.Tx.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
 var _setOrderCourierID = `
-	UPDATE orders SET courier_id = $1 WHERE order_id = $2
+	UPDATE orders SET courier_id = $1, group_order_id = $2, distribution_date = $3 WHERE order_id = $4
+`
+
+// _getCourierWithActivity locks the courier, so the requests changing its
+// orders on a date check its capacity one after another.
+var _getCourierWithActivity = `
+	SELECT courier_id, courier_type, regions, working_hours, active
+	FROM couriers
+	WHERE courier_id = $1
+	FOR UPDATE;
+`
+
+func getCourierWithActivity(ctx context.Context, q querier, courierID uuid.UUID) (*entity.CourierResponse, bool, error) {
+	var courier entity.CourierResponse
+	var active bool
+
+	err := q.QueryRow(ctx, _getCourierWithActivity, courierID).Scan(&courier.CourierID, &courier.CourierType, &courier.Regions, &courier.WorkingHours, &active)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, fmt.Errorf("getCourierWithActivity - %w", entity.NewDomainError(entity.ErrNotFound, "courier not found"))
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("getCourierWithActivity - q.QueryRow: %w", mapPgError(err))
+	}
+
+	return &courier, active, nil
+}
+
+// _lockCouriers locks the couriers in the order of their ids, so requests
+// locking several of them can't deadlock each other.
+var _lockCouriers = `
+	SELECT courier_id
+	FROM couriers
+	WHERE courier_id = ANY($1::uuid[])
+	ORDER BY courier_id
+	FOR UPDATE;
+`
+
+// lockCouriers serializes the requests changing the orders of the couriers.
+// Every such request locks the couriers before it locks their orders:
+// SetCourierID, Reassign and the save of an assignment run.
+func lockCouriers(ctx context.Context, q querier, courierIDs []uuid.UUID) error {
+	if _, err := q.Exec(ctx, _lockCouriers, courierIDs); err != nil {
+		return fmt.Errorf("lockCouriers - q.Exec: %w", mapPgError(err))
+	}
+
+	return nil
+}
+
+var _getCourierOrdersOnDate = `
+	SELECT order_id, courier_id, weight, regions, delivery_hours, distribution_date, group_order_id
+	FROM orders
+	WHERE courier_id = $1 AND distribution_date = $2 AND NOT (order_id = ANY($3))
+	ORDER BY order_id;
+`
+
+// getCourierOrdersOnDate returns the orders the courier delivers on the
+// date, completed or not, ignoring the given orders.
+func getCourierOrdersOnDate(ctx context.Context, q querier, courierID uuid.UUID, date time.Time, ignoredOrderIDs []uuid.UUID) ([]*entity.Order, error) {
+	rows, err := q.Query(ctx, _getCourierOrdersOnDate, courierID, date, ignoredOrderIDs)
+	if err != nil {
+		return nil, fmt.Errorf("getCourierOrdersOnDate - q.Query: %w", mapPgError(err))
+	}
+	defer rows.Close()
+
+	orders := make([]*entity.Order, 0)
+	for rows.Next() {
+		var order entity.Order
+		err := rows.Scan(&order.OrderID, &order.CourierID, &order.Weight, &order.Regions, &order.DeliveryHours, &order.DistributionDate, &order.GroupOrderID)
+		if err != nil {
+			return nil, fmt.Errorf("getCourierOrdersOnDate - rows.Scan: %w", err)
+		}
+
+		orders = append(orders, &order)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("getCourierOrdersOnDate - rows.Err: %w", mapPgError(err))
+	}
+
+	return orders, nil
+}
+
+// groupLoad is what a group of a courier carries.
+type groupLoad struct {
+	region int
+	count  int
+	weight float32
+	isNew  bool
+}
+
+// checkCourierCanTakeOrders returns every reason why the courier can't
+// deliver the orders in addition to the orders it already has on the date.
+// The orders keep their stored groups: every group with a new order must fit
+// the count and weight limits of the courier type, and every region the
+// working time the groups take, the same way the assignment fills them.
+func checkCourierCanTakeOrders(courier *entity.CourierResponse, active bool, orders, ordersOnDate []*entity.Order, date time.Time) []string {
+	limits := getCourierLimits(courier.CourierType)
+	reasons := make([]string, 0)
+
+	if !active {
+		reasons = append(reasons, "courier is not active")
+	}
+
+	for _, order := range orders {
+		if !containRegion(courier.Regions, order.Regions) {
+			reasons = append(reasons, fmt.Sprintf("order %s: courier doesn't serve region %d", order.OrderID, order.Regions))
+		}
+
+		if order.Weight > limits.maxWeight {
+			reasons = append(reasons, fmt.Sprintf("order %s: weight %g exceeds the %s courier limit of %g", order.OrderID, order.Weight, courier.CourierType, limits.maxWeight))
+		}
+
+		if workingWindowMinutes(courier, &order.OrderResponse, limits.overlap) == 0 {
+			reasons = append(reasons, fmt.Sprintf("order %s: courier working hours don't overlap the delivery hours by at least %s", order.OrderID, limits.overlap))
+		}
+	}
+
+	groups := make(map[uuid.UUID]*groupLoad)
+	groupIDs := make([]uuid.UUID, 0)
+	regions := make([]int, 0)
+	firstOrders := make(map[int]*entity.OrderResponse) // mapping: region -> first order, that sets the working window
+	newRegions := make(map[int]bool)
+
+	load := func(order *entity.Order, isNew bool) {
+		groupID := storedGroupOrderID(order)
+
+		group, ok := groups[groupID]
+		if !ok {
+			group = &groupLoad{region: order.Regions}
+			groups[groupID] = group
+			groupIDs = append(groupIDs, groupID)
+		}
+		group.count++
+		group.weight += order.Weight
+		group.isNew = group.isNew || isNew
+
+		if !containRegion(regions, order.Regions) {
+			regions = append(regions, order.Regions)
+			firstOrders[order.Regions] = &order.OrderResponse
+		}
+		if isNew {
+			newRegions[order.Regions] = true
+		}
+	}
+
+	for _, order := range ordersOnDate {
+		load(order, false)
+	}
+	for _, order := range orders {
+		load(order, true)
+	}
+
+	if len(regions) > limits.maxRegions {
+		reasons = append(reasons, fmt.Sprintf("courier would deliver to %d regions on %s, the %s courier limit is %d",
+			len(regions), date.Format("2006-01-02"), courier.CourierType, limits.maxRegions))
+	}
+
+	overlap := int(limits.overlap.Minutes())
+	usedMinutes := make(map[int]int) // mapping: region -> minutes the groups take
+	for _, groupID := range groupIDs {
+		group := groups[groupID]
+		usedMinutes[group.region] += overlap + (group.count-1)*limits.nextDeliveryTime
+
+		if !group.isNew || group.count == 1 {
+			continue
+		}
+
+		if group.count > limits.maxCount {
+			reasons = append(reasons, fmt.Sprintf("group %s: %d orders exceed the %s courier limit of %d", groupID, group.count, courier.CourierType, limits.maxCount))
+		}
+
+		if group.weight > limits.maxWeight {
+			reasons = append(reasons, fmt.Sprintf("group %s: weight %g exceeds the %s courier limit of %g", groupID, group.weight, courier.CourierType, limits.maxWeight))
+		}
+	}
+
+	for _, region := range regions {
+		window := workingWindowMinutes(courier, firstOrders[region], limits.overlap)
+		if !newRegions[region] || window == 0 {
+			continue
+		}
+
+		if usedMinutes[region] > window {
+			reasons = append(reasons, fmt.Sprintf("region %d: the groups take %d minutes, the courier works %d", region, usedMinutes[region], window))
+		}
+	}
+
+	return reasons
+}
+
+var _recordAssignmentChange = `
+	INSERT INTO assignment_changes (order_id, from_courier_id, to_courier_id, distribution_date, reason, changed_at)
+	VALUES ($1, $2, $3, $4, $5, $6);
 `
 
+// nullableCourierID maps the zero uuid of an unassigned order to NULL.
+func nullableCourierID(courierID uuid.UUID) *uuid.UUID {
+	if courierID == uuid.Nil {
+		return nil
+	}
+
+	return &courierID
+}
+
+func recordAssignmentChange(ctx context.Context, q querier, order *entity.Order, toCourierID uuid.UUID, reason string) error {
+	_, err := q.Exec(ctx, _recordAssignmentChange, order.OrderID, nullableCourierID(order.CourierID), nullableCourierID(toCourierID), order.DistributionDate, reason, time.Now())
+	if err != nil {
+		return fmt.Errorf("recordAssignmentChange - q.Exec: %w", mapPgError(err))
+	}
+
+	return nil
+}
+
 func (r *OrderRepo) SetCourierID(ctx context.Context, orderID uuid.UUID, courierID uuid.UUID) (*entity.OrderResponse, error) {
-	order, err := r.Get(ctx, orderID)
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("OrderRepo - SetCourierID - r.Pool.Begin: %w", mapPgError(err))
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	if err := lockCouriers(ctx, tx, []uuid.UUID{courierID}); err != nil {
+		return nil, fmt.Errorf("OrderRepo - SetCourierID - %w", err)
+	}
 
+	order, err := getFullOrderForUpdate(ctx, tx, orderID)
 	if err != nil {
-		return nil, fmt.Errorf("OrderRepo - SetCourierID - r.Get: %w", err)
+		return nil, fmt.Errorf("OrderRepo - SetCourierID - %w", err)
+	}
+
+	if !order.CompletedTime.IsZero() {
+		return nil, fmt.Errorf("OrderRepo - SetCourierID - %w", entity.NewDomainError(entity.ErrForbiddenTransition, "the order is already completed"))
 	}
 
-	_, err = r.Pool.Exec(ctx, _checkIfCourierExists, courierID)
+	courier, active, err := getCourierWithActivity(ctx, tx, courierID)
 	if err != nil {
-		return nil, fmt.Errorf("OrderRepo - SetCourierID - r.Pool.Exec(_checkIfCourierExists): %w", err)
+		return nil, fmt.Errorf("OrderRepo - SetCourierID - %w", err)
 	}
 
-	order.CourierID = courierID
+	// An order that isn't distributed yet is planned for today, so the next
+	// assignment doesn't give it to another courier
+	date := order.DistributionDate
+	if date.IsZero() {
+		date = entity.Today()
+	}
 
-	_, err = r.Pool.Exec(ctx, _setOrderCourierID, courierID, orderID)
+	ordersOnDate, err := getCourierOrdersOnDate(ctx, tx, courierID, date, []uuid.UUID{order.OrderID})
 	if err != nil {
-		return nil, fmt.Errorf("OrderRepo - SetCourierID - r.Pool.Exec(_setOrderCourierID): %w", err)
+		return nil, fmt.Errorf("OrderRepo - SetCourierID - %w", err)
+	}
+
+	// The order makes a group of its own
+	set := *order
+	set.DistributionDate = date
+	set.GroupOrderID = newGroupOrderID(courierID, date, order.OrderID)
+
+	reasons := checkCourierCanTakeOrders(courier, active, []*entity.Order{&set}, ordersOnDate, date)
+	if len(reasons) > 0 {
+		return nil, fmt.Errorf("OrderRepo - SetCourierID - %w", entity.NewDomainError(entity.ErrConflict, "courier can't take the order").WithDetails(reasons...))
+	}
+
+	_, err = tx.Exec(ctx, _setOrderCourierID, courierID, set.GroupOrderID, date, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("OrderRepo - SetCourierID - tx.Exec(_setOrderCourierID): %w", mapPgError(err))
+	}
+
+	if err := recordAssignmentChange(ctx, tx, order, courierID, "set_courier"); err != nil {
+		return nil, fmt.Errorf("OrderRepo - SetCourierID - %w", err)
 	}
 
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("OrderRepo - SetCourierID - tx.Commit: %w", mapPgError(err))
+	}
+
+	order.CourierID = courierID
+
 	orderRes := &entity.OrderResponse{
 		OrderID:       order.OrderID,
 		CourierID:     order.CourierID,
@@ -209,15 +568,237 @@ func (r *OrderRepo) SetCourierID(ctx context.Context, orderID uuid.UUID, courier
 		DeliveryHours: order.DeliveryHours,
 		Cost:          order.Cost,
 		CompletedTime: order.CompletedTime,
+		OnTime:        order.OnTime,
 	}
 
 	return orderRes, nil
 }
 
+// _unassignOrder also drops the start of the delivery, so the order doesn't
+// come back to a plan as already on the trip.
+var _unassignOrder = `
+	UPDATE orders
+	SET courier_id = NULL,
+		group_order_id = NULL,
+		distribution_date = '0001-01-01 00:00:00',
+		started_time = '0001-01-01 00:00:00'
+	WHERE order_id = $1;
+`
+
+func (r *OrderRepo) Unassign(ctx context.Context, orderID uuid.UUID) (*entity.OrderResponse, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("OrderRepo - Unassign - r.Pool.Begin: %w", mapPgError(err))
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	// The order stays locked until the change is recorded, so a concurrent
+	// completion or reassignment can't slip in between the checks and the update
+	order, err := getFullOrderForUpdate(ctx, tx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("OrderRepo - Unassign - %w", err)
+	}
+
+	if !order.CompletedTime.IsZero() {
+		return nil, fmt.Errorf("OrderRepo - Unassign - %w", entity.NewDomainError(entity.ErrForbiddenTransition, "the order is already completed"))
+	}
+
+	if order.CourierID == uuid.Nil {
+		return nil, fmt.Errorf("OrderRepo - Unassign - %w", entity.NewDomainError(entity.ErrForbiddenTransition, "the order is not assigned to a courier"))
+	}
+
+	_, err = tx.Exec(ctx, _unassignOrder, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("OrderRepo - Unassign - tx.Exec(_unassignOrder): %w", mapPgError(err))
+	}
+
+	if err := recordAssignmentChange(ctx, tx, order, uuid.Nil, "unassign"); err != nil {
+		return nil, fmt.Errorf("OrderRepo - Unassign - %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("OrderRepo - Unassign - tx.Commit: %w", mapPgError(err))
+	}
+
+	orderRes := &entity.OrderResponse{
+		OrderID:       order.OrderID,
+		Weight:        order.Weight,
+		Regions:       order.Regions,
+		DeliveryHours: order.DeliveryHours,
+		Cost:          order.Cost,
+		CompletedTime: order.CompletedTime,
+		OnTime:        order.OnTime,
+	}
+
+	return orderRes, nil
+}
+
+var _getOpenOrdersOfCourierOnDate = `
+	SELECT order_id, courier_id, weight, regions, delivery_hours, cost, completed_time, on_time, distribution_date, group_order_id, created_at, updated_at
+	FROM orders
+	WHERE courier_id = $1 AND distribution_date = $2 AND completed_time = '0001-01-01 00:00:00'
+	FOR UPDATE;
+`
+
+func getOpenOrdersOfCourierOnDate(ctx context.Context, q querier, courierID uuid.UUID, date time.Time) ([]*entity.Order, error) {
+	rows, err := q.Query(ctx, _getOpenOrdersOfCourierOnDate, courierID, date)
+	if err != nil {
+		return nil, fmt.Errorf("getOpenOrdersOfCourierOnDate - q.Query: %w", mapPgError(err))
+	}
+	defer rows.Close()
+
+	orders := make([]*entity.Order, 0)
+	for rows.Next() {
+		var order entity.Order
+		err := rows.Scan(&order.OrderID, &order.CourierID, &order.Weight, &order.Regions, &order.DeliveryHours, &order.Cost, &order.CompletedTime, &order.OnTime, &order.DistributionDate, &order.GroupOrderID, &order.CreatedAt, &order.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("getOpenOrdersOfCourierOnDate - rows.Scan: %w", err)
+		}
+
+		orders = append(orders, &order)
+	}
+
+	return orders, nil
+}
+
+// Reassign moves all open orders of one courier on the date to another
+// courier and returns the assignments of the new courier on that date.
+func (r *OrderRepo) Reassign(ctx context.Context, date time.Time, fromCourierID uuid.UUID, toCourierID uuid.UUID) (*entity.CourierAssignment, error) {
+	if fromCourierID == toCourierID {
+		return nil, fmt.Errorf("OrderRepo - Reassign - %w", entity.NewDomainError(entity.ErrValidation, "from_courier_id and to_courier_id must differ"))
+	}
+
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("OrderRepo - Reassign - r.Pool.Begin: %w", mapPgError(err))
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	if err := lockCouriers(ctx, tx, []uuid.UUID{fromCourierID, toCourierID}); err != nil {
+		return nil, fmt.Errorf("OrderRepo - Reassign - %w", err)
+	}
+
+	orders, err := getOpenOrdersOfCourierOnDate(ctx, tx, fromCourierID, date)
+	if err != nil {
+		return nil, fmt.Errorf("OrderRepo - Reassign - %w", err)
+	}
+
+	if len(orders) == 0 {
+		return nil, fmt.Errorf("OrderRepo - Reassign - %w", entity.NewDomainError(entity.ErrNotFound, "the courier has no open orders on the date"))
+	}
+
+	courier, active, err := getCourierWithActivity(ctx, tx, toCourierID)
+	if err != nil {
+		return nil, fmt.Errorf("OrderRepo - Reassign - %w", err)
+	}
+
+	ordersOnDate, err := getCourierOrdersOnDate(ctx, tx, toCourierID, date, []uuid.UUID{})
+	if err != nil {
+		return nil, fmt.Errorf("OrderRepo - Reassign - %w", err)
+	}
+
+	// The groups move to the new courier as they are
+	moved := make([]*entity.Order, 0, len(orders))
+	for _, order := range orders {
+		m := *order
+		m.GroupOrderID = movedGroupOrderID(toCourierID, storedGroupOrderID(order))
+		moved = append(moved, &m)
+	}
+
+	reasons := checkCourierCanTakeOrders(courier, active, moved, ordersOnDate, date)
+	if len(reasons) > 0 {
+		return nil, fmt.Errorf("OrderRepo - Reassign - %w", entity.NewDomainError(entity.ErrConflict, "courier can't take the orders").WithDetails(reasons...))
+	}
+
+	for i, order := range orders {
+		_, err = tx.Exec(ctx, _setOrderCourierID, toCourierID, moved[i].GroupOrderID, date, order.OrderID)
+		if err != nil {
+			return nil, fmt.Errorf("OrderRepo - Reassign - tx.Exec(_setOrderCourierID): %w", mapPgError(err))
+		}
+
+		if err := recordAssignmentChange(ctx, tx, order, toCourierID, "reassign"); err != nil {
+			return nil, fmt.Errorf("OrderRepo - Reassign - %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("OrderRepo - Reassign - tx.Commit: %w", mapPgError(err))
+	}
+
+	assignments, err := NewCourierRepo(r.Postgres).GetAssignments(ctx, date, toCourierID, false)
+	if err != nil {
+		return nil, fmt.Errorf("OrderRepo - Reassign - courierRepo.GetAssignments: %w", err)
+	}
+
+	for _, assignment := range assignments {
+		if assignment.CourierID == toCourierID {
+			return assignment, nil
+		}
+	}
+
+	return &entity.CourierAssignment{CourierID: toCourierID, Orders: make([]entity.OrdersGroup, 0)}, nil
+}
+
+var _getOrderForUpdate = `
+	SELECT order_id, courier_id, weight, regions, delivery_hours, cost, completed_time, on_time, started_time
+	FROM orders
+	WHERE order_id = $1
+	FOR UPDATE;
+`
+
+var _setOrderStartedTime = `
+	UPDATE orders SET started_time = $1 WHERE order_id = $2;
+`
+
+// StartDelivery marks the orders of a group as taken on the trip. Orders
+// that are already started keep their started_time.
+func (r *OrderRepo) StartDelivery(ctx context.Context, courierID uuid.UUID, orderIDs []uuid.UUID, startTime time.Time) ([]*entity.OrderResponse, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("OrderRepo - StartDelivery - r.Pool.Begin: %w", mapPgError(err))
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	orders := make([]*entity.OrderResponse, 0, len(orderIDs))
+	for _, orderID := range orderIDs {
+		var order entity.Order
+		var orderCourierID *uuid.UUID
+
+		err := tx.QueryRow(ctx, _getOrderForUpdate, orderID).Scan(&order.OrderID, &orderCourierID, &order.Weight, &order.Regions, &order.DeliveryHours, &order.Cost, &order.CompletedTime, &order.OnTime, &order.StartedTime)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, fmt.Errorf("OrderRepo - StartDelivery - %w", entity.NewDomainError(entity.ErrNotFound, "order not found"))
+			}
+			return nil, fmt.Errorf("OrderRepo - StartDelivery - tx.QueryRow: %w", mapPgError(err))
+		}
+
+		if orderCourierID == nil || *orderCourierID != courierID {
+			return nil, fmt.Errorf("OrderRepo - StartDelivery - %w", entity.NewDomainError(entity.ErrForbiddenTransition, "courier_id and the order courier don't match"))
+		}
+		order.CourierID = courierID
+
+		if order.StartedTime.IsZero() && order.CompletedTime.IsZero() {
+			_, err = tx.Exec(ctx, _setOrderStartedTime, startTime, orderID)
+			if err != nil {
+				return nil, fmt.Errorf("OrderRepo - StartDelivery - tx.Exec(_setOrderStartedTime): %w", mapPgError(err))
+			}
+		}
+
+		orders = append(orders, &order.OrderResponse)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("OrderRepo - StartDelivery - tx.Commit: %w", mapPgError(err))
+	}
+
+	return orders, nil
+}
+
 var _getOrdersLower40kgAndNotDistributed = `
-	SELECT order_id, courier_id, weight, regions, delivery_hours, cost, completed_time
+	SELECT order_id, courier_id, weight, regions, delivery_hours, cost, completed_time, on_time, address, latitude, longitude
 	FROM orders
-	WHERE weight < 40 AND distribution_date = '0001-01-01 00:00:00';
+	WHERE weight < 40 AND distribution_date = '0001-01-01 00:00:00' AND courier_id IS NULL
+	ORDER BY weight DESC, order_id;
 `
 
 func (r *OrderRepo) getOrdersForAssign(ctx context.Context) ([]*entity.OrderResponse, error) {
@@ -225,16 +806,18 @@ func (r *OrderRepo) getOrdersForAssign(ctx context.Context) ([]*entity.OrderResp
 
 	rows, err := r.Pool.Query(ctx, _getOrdersLower40kgAndNotDistributed)
 	if err != nil {
-		return nil, fmt.Errorf("OrderRepo - getOrdersForAssign - r.Pool.Query: %w", err)
+		return nil, fmt.Errorf("OrderRepo - getOrdersForAssign - r.Pool.Query: %w", mapPgError(err))
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		var order entity.OrderResponse
-		err := rows.Scan(&order.OrderID, &order.CourierID, &order.Weight, &order.Regions, &order.DeliveryHours, &order.Cost, &order.CompletedTime)
+		var address addressColumns
+		err := rows.Scan(&order.OrderID, &order.CourierID, &order.Weight, &order.Regions, &order.DeliveryHours, &order.Cost, &order.CompletedTime, &order.OnTime, &address.address, &address.latitude, &address.longitude)
 		if err != nil {
 			return nil, fmt.Errorf("OrderRepo - getOrdersForAssign - rows.Scan: %w", err)
 		}
+		order.Address = address.entity()
 		orders = append(orders, &order)
 	}
 
@@ -246,23 +829,61 @@ type timeRange struct {
 	end   time.Time
 }
 
-func parseTime(t1 string, t2 string) (timeRange, timeRange) {
-	var tr1, tr2 timeRange
+// clockTime drops the date part of t so it can be compared with the
+// "15:04" bounds of working and delivery hours.
+func clockTime(t time.Time) time.Time {
+	return time.Date(0, time.January, 1, t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+}
 
+func parseTimeRange(s string) timeRange {
 	layout := "15:04"
-	parts := strings.Split(t1, "-")
+	parts := strings.Split(s, "-")
 	startTime, _ := time.Parse(layout, parts[0]) // We can't get an error cause we validate it in controller
 	endTime, _ := time.Parse(layout, parts[1])
 
-	tr1.start, tr1.end = startTime, endTime
+	return timeRange{start: startTime, end: endTime}
+}
+
+func inTimeRanges(t time.Time, ranges []string) bool {
+	clock := clockTime(t)
+	for _, s := range ranges {
+		tr := parseTimeRange(s)
+		if !clock.Before(tr.start) && !clock.After(tr.end) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// deliveryStatus tells if the order completed at t was delivered on time,
+// before its delivery hours or after them.
+func deliveryStatus(t time.Time, deliveryHours []string) string {
+	switch {
+	case inTimeRanges(t, deliveryHours):
+		return entity.DeliveryOnTime
+	case isEarlyDelivery(t, deliveryHours):
+		return entity.DeliveryEarly
+	default:
+		return entity.DeliveryLate
+	}
+}
 
-	parts = strings.Split(t2, "-")
-	startTime, _ = time.Parse(layout, parts[0])
-	endTime, _ = time.Parse(layout, parts[1])
+// isEarlyDelivery reports whether t is before the start of every delivery window,
+// otherwise a delivery outside of the windows is considered late.
+func isEarlyDelivery(t time.Time, deliveryHours []string) bool {
+	clock := clockTime(t)
+	for _, s := range deliveryHours {
+		if !clock.Before(parseTimeRange(s).start) {
+			return false
+		}
+	}
 
-	tr2.start, tr2.end = startTime, endTime
+	return true
+}
 
-	return tr1, tr2
+func parseTime(t1 string, t2 string) (timeRange, timeRange) {
+	return parseTimeRange(t1), parseTimeRange(t2)
 }
 
 func checkTimeOverlap(t1 timeRange, t2 timeRange, overlap time.Duration) bool {
@@ -299,15 +920,9 @@ type ordersGroupWithLeftTime struct {
 }
 
 func initOrdersGroup(assignments map[uuid.UUID]map[int]ordersGroupWithLeftTime, courierID uuid.UUID, order entity.OrderResponse, leftTime int) {
-	assignments[courierID][order.Regions] = ordersGroupWithLeftTime{
-		orders:   make([]entity.OrdersGroup, 0),
-		leftTime: leftTime,
-	}
-
 	orders := assignments[courierID][order.Regions].orders
 	orderG := entity.OrdersGroup{
-		GroupOrderID: uuid.New(),
-		Orders:       make([]entity.OrderResponse, 0),
+		Orders: make([]entity.OrderResponse, 0),
 	}
 
 	orderG.Orders = append(orderG.Orders, order)
@@ -319,31 +934,61 @@ func initOrdersGroup(assignments map[uuid.UUID]map[int]ordersGroupWithLeftTime,
 	}
 }
 
-func findAndSetFreeCourier(couriers []*entity.CourierResponse, order *entity.OrderResponse, assignments map[uuid.UUID]map[int]ordersGroupWithLeftTime) bool {
+// newGroupOrderID derives the id of a new group of the courier on the date
+// from the first order put into it. The id is stored with the orders of the
+// group, so it doesn't change when the group or the other groups change.
+func newGroupOrderID(courierID uuid.UUID, date time.Time, firstOrderID uuid.UUID) uuid.UUID {
+	return uuid.NewSHA1(courierID, []byte(date.Format("2006-01-02")+"/"+firstOrderID.String()))
+}
+
+// storedGroupOrderID returns the group of a distributed order. The orders
+// distributed without a group make a group of their own.
+func storedGroupOrderID(order *entity.Order) uuid.UUID {
+	if order.GroupOrderID != uuid.Nil {
+		return order.GroupOrderID
+	}
+
+	return newGroupOrderID(order.CourierID, order.DistributionDate, order.OrderID)
+}
+
+// movedGroupOrderID is the id a group gets when it's moved to another
+// courier, the orders left with the first courier keep the old one.
+func movedGroupOrderID(toCourierID uuid.UUID, groupOrderID uuid.UUID) uuid.UUID {
+	return uuid.NewSHA1(toCourierID, groupOrderID[:])
+}
+
+// courierLimits describes what a courier of the given type can handle
+// during the day.
+type courierLimits struct {
+	overlap          time.Duration // min overlap of working and delivery hours
+	maxRegions       int
+	maxCount         int // max orders in one group
+	maxWeight        float32
+	nextDeliveryTime int     // minutes to deliver the next order of a group
+	speed            float64 // km/h between the orders with an address
+}
+
+func getCourierLimits(courierType string) courierLimits {
+	switch courierType {
+	case "FOOT":
+		return courierLimits{overlap: 25 * time.Minute, maxRegions: 1, maxCount: 2, maxWeight: 10, nextDeliveryTime: 10, speed: 5}
+	case "BIKE":
+		return courierLimits{overlap: 12 * time.Minute, maxRegions: 2, maxCount: 4, maxWeight: 20, nextDeliveryTime: 8, speed: 15}
+	case "AUTO":
+		return courierLimits{overlap: 8 * time.Minute, maxRegions: 3, maxCount: 7, maxWeight: 40, nextDeliveryTime: 4, speed: 25}
+	}
+
+	return courierLimits{}
+}
+
+// findAndSetFreeCourier puts the order into the first courier that can take
+// it. With adjacentOnly a courier only takes a new region bordering one of
+// the regions it already serves.
+func findAndSetFreeCourier(l logger.Interface, couriers []*entity.CourierResponse, order *entity.OrderResponse, assignments map[uuid.UUID]map[int]ordersGroupWithLeftTime, adjacency regionAdjacency, adjacentOnly bool) bool {
 	for _, courier := range couriers {
 		if containRegion(courier.Regions, order.Regions) {
-			var overlap time.Duration
-			var maxRegions int
-			var maxCount int
-			var nextDeliveryTime int
-
-			switch courier.CourierType {
-			case "FOOT":
-				overlap = 25 * time.Minute
-				maxRegions = 1
-				maxCount = 2
-				nextDeliveryTime = 10
-			case "BIKE":
-				overlap = 12 * time.Minute
-				maxRegions = 2
-				maxCount = 4
-				nextDeliveryTime = 8
-			case "AUTO":
-				overlap = 8 * time.Minute
-				maxRegions = 3
-				maxCount = 7
-				nextDeliveryTime = 4
-			}
+			limits := getCourierLimits(courier.CourierType)
+			overlap, maxRegions, maxCount, maxWeight, nextDeliveryTime := limits.overlap, limits.maxRegions, limits.maxCount, limits.maxWeight, limits.nextDeliveryTime
 
 			for i := 0; i < len(courier.WorkingHours); i++ {
 				for j := 0; j < len(order.DeliveryHours); j++ {
@@ -351,10 +996,10 @@ func findAndSetFreeCourier(couriers []*entity.CourierResponse, order *entity.Ord
 					if checkTimeOverlap(courierTime, orderTime, overlap) {
 						if reg, ok := assignments[courier.CourierID]; ok {
 							if orderGroup, ok := reg[order.Regions]; ok {
-								for _, orderG := range orderGroup.orders {
-									if len(orderG.Orders) < maxCount {
+								for k := range orderGroup.orders {
+									if len(orderGroup.orders[k].Orders) < maxCount && groupWeight(orderGroup.orders[k])+order.Weight <= maxWeight {
 										if orderGroup.leftTime > nextDeliveryTime {
-											orderG.Orders = append(orderG.Orders, *order)
+											orderGroup.orders[k].Orders = append(orderGroup.orders[k].Orders, *order)
 											assignments[courier.CourierID][order.Regions] = ordersGroupWithLeftTime{
 												orders:   orderGroup.orders,
 												leftTime: orderGroup.leftTime - nextDeliveryTime,
@@ -368,18 +1013,20 @@ func findAndSetFreeCourier(couriers []*entity.CourierResponse, order *entity.Ord
 									initOrdersGroup(assignments, courier.CourierID, *order, orderGroup.leftTime-int(overlap.Minutes()))
 									return true
 								}
-								log.Println("doesn't have enough time", orderGroup.leftTime, int(overlap.Minutes()))
+								l.With("courier_id", courier.CourierID).With("order_id", order.OrderID).With("left_minutes", orderGroup.leftTime).
+									Debug("OrderRepo - findAndSetFreeCourier - courier doesn't have enough time")
 							} else {
-								if len(reg) <= maxRegions {
-									// reg[order.Regions] = ordersGroupWithLeftTime{
-									// 	orders:   make([]entity.OrdersGroup, 0),
-									// 	leftTime: int(courierTime.end.Sub(courierTime.start).Minutes()),
-									// }
-
+								switch {
+								case len(reg) >= maxRegions:
+									l.With("courier_id", courier.CourierID).With("order_id", order.OrderID).
+										Debug("OrderRepo - findAndSetFreeCourier - courier reached max regions")
+								case adjacentOnly && !bordersAny(adjacency, reg, order.Regions):
+									l.With("courier_id", courier.CourierID).With("order_id", order.OrderID).
+										Debug("OrderRepo - findAndSetFreeCourier - region doesn't border the courier regions")
+								default:
 									initOrdersGroup(assignments, courier.CourierID, *order, int(courierTime.end.Sub(courierTime.start).Minutes())-int(overlap.Minutes()))
 									return true
 								}
-								log.Println("Got max regions limit")
 							}
 						} else {
 							assignments[courier.CourierID] = make(map[int]ordersGroupWithLeftTime)
@@ -387,7 +1034,8 @@ func findAndSetFreeCourier(couriers []*entity.CourierResponse, order *entity.Ord
 							return true
 						}
 					} else {
-						log.Println("Time is not overlap: ", courier, order)
+						l.With("courier_id", courier.CourierID).With("order_id", order.OrderID).
+							Debug("OrderRepo - findAndSetFreeCourier - working and delivery hours don't overlap")
 					}
 				}
 			}
@@ -397,10 +1045,185 @@ func findAndSetFreeCourier(couriers []*entity.CourierResponse, order *entity.Ord
 	return false
 }
 
+// groupWeight returns the weight of the orders of the group.
+func groupWeight(group entity.OrdersGroup) float32 {
+	var weight float32
+	for i := range group.Orders {
+		weight += group.Orders[i].Weight
+	}
+
+	return weight
+}
+
+// bordersAny tells if the region borders one of the regions of the courier.
+func bordersAny(adjacency regionAdjacency, courierRegions map[int]ordersGroupWithLeftTime, region int) bool {
+	for courierRegion := range courierRegions {
+		if adjacency.adjacent(courierRegion, region) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// workingWindowMinutes returns the length of the first courier working
+// interval that overlaps the order delivery hours, the same interval
+// findAndSetFreeCourier starts a new region from.
+func workingWindowMinutes(courier *entity.CourierResponse, order *entity.OrderResponse, overlap time.Duration) int {
+	for _, workingHours := range courier.WorkingHours {
+		for _, deliveryHours := range order.DeliveryHours {
+			courierTime, orderTime := parseTime(workingHours, deliveryHours)
+			if checkTimeOverlap(courierTime, orderTime, overlap) {
+				return int(courierTime.end.Sub(courierTime.start).Minutes())
+			}
+		}
+	}
+
+	return 0
+}
+
+// seedAssignments puts the orders already assigned on the date into their
+// stored groups, so new orders only get the capacity the couriers have left.
+func seedAssignments(couriers map[uuid.UUID]*entity.CourierResponse, orders []*entity.Order, assignments map[uuid.UUID]map[int]ordersGroupWithLeftTime) {
+	for _, order := range orders {
+		courier, ok := couriers[order.CourierID]
+		if !ok {
+			continue
+		}
+
+		limits := getCourierLimits(courier.CourierType)
+		overlap := int(limits.overlap.Minutes())
+
+		reg, ok := assignments[courier.CourierID]
+		if !ok {
+			reg = make(map[int]ordersGroupWithLeftTime)
+			assignments[courier.CourierID] = reg
+		}
+
+		groupID := storedGroupOrderID(order)
+
+		orderGroup, ok := reg[order.Regions]
+		if k := groupIndex(orderGroup.orders, groupID); k >= 0 {
+			orderGroup.orders[k].Orders = append(orderGroup.orders[k].Orders, order.OrderResponse)
+			setGroupStartedTime(&orderGroup.orders[k], order.StartedTime)
+			orderGroup.leftTime -= limits.nextDeliveryTime
+			reg[order.Regions] = orderGroup
+
+			continue
+		}
+
+		leftTime := workingWindowMinutes(courier, &order.OrderResponse, limits.overlap) - overlap
+		if ok {
+			leftTime = orderGroup.leftTime - overlap
+		}
+
+		initOrdersGroup(assignments, courier.CourierID, order.OrderResponse, leftTime)
+		groups := assignments[courier.CourierID][order.Regions].orders
+		groups[len(groups)-1].GroupOrderID = groupID
+		setGroupStartedTime(&groups[len(groups)-1], order.StartedTime)
+	}
+}
+
+func groupIndex(groups []entity.OrdersGroup, groupOrderID uuid.UUID) int {
+	for i := range groups {
+		if groups[i].GroupOrderID == groupOrderID {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// distributeOrders puts the orders into the groups of the couriers, the
+// heaviest first, and returns the number of orders left without a courier by
+// region. The ids order the orders of equal weight, so the same data is
+// always distributed the same way.
+//
+// When the catalog knows the neighbors, couriers serving several regions
+// first only take regions bordering theirs, and any region on the second
+// pass.
+func distributeOrders(l logger.Interface, footCouriers, bikeCouriers, autoCouriers []*entity.CourierResponse, orders []*entity.OrderResponse, assignments map[uuid.UUID]map[int]ordersGroupWithLeftTime, adjacency regionAdjacency) map[int]int {
+	sort.Slice(orders, func(i, j int) bool {
+		if orders[i].Weight != orders[j].Weight {
+			return orders[i].Weight > orders[j].Weight
+		}
+
+		return bytes.Compare(orders[i].OrderID[:], orders[j].OrderID[:]) < 0
+	})
+
+	unassigned := make(map[int]int) // mapping: region -> orders left without a courier
+	for _, order := range orders {
+		tiers := [][]*entity.CourierResponse{autoCouriers}
+		if order.Weight <= 20 {
+			tiers = append(tiers, bikeCouriers)
+		}
+		if order.Weight <= 10 {
+			tiers = append(tiers, footCouriers)
+		}
+
+		assign := func(adjacentOnly bool) bool {
+			for _, couriers := range tiers {
+				if findAndSetFreeCourier(l, couriers, order, assignments, adjacency, adjacentOnly) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		assigned := len(adjacency) > 0 && assign(true)
+		if !assigned {
+			assigned = assign(false)
+		}
+
+		if !assigned {
+			unassigned[order.Regions]++
+			l.With("order_id", order.OrderID).Debug("OrderRepo - distributeOrders - no free courier for the order")
+		}
+	}
+
+	return unassigned
+}
+
+var _getAssignedOrdersOnDate = `
+	SELECT order_id, courier_id, weight, regions, delivery_hours, cost, completed_time, on_time, started_time, address, latitude, longitude, distribution_date, group_order_id
+	FROM orders
+	WHERE distribution_date = $1 AND courier_id IS NOT NULL
+	ORDER BY weight DESC, order_id;
+`
+
+func (r *OrderRepo) getAssignedOrdersOnDate(ctx context.Context, date time.Time) ([]*entity.Order, error) {
+	var orders []*entity.Order
+
+	rows, err := r.Pool.Query(ctx, _getAssignedOrdersOnDate, date)
+	if err != nil {
+		return nil, fmt.Errorf("OrderRepo - getAssignedOrdersOnDate - r.Pool.Query: %w", mapPgError(err))
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var order entity.Order
+		var address addressColumns
+		err := rows.Scan(&order.OrderID, &order.CourierID, &order.Weight, &order.Regions, &order.DeliveryHours, &order.Cost, &order.CompletedTime, &order.OnTime, &order.StartedTime, &address.address, &address.latitude, &address.longitude, &order.DistributionDate, &order.GroupOrderID)
+		if err != nil {
+			return nil, fmt.Errorf("OrderRepo - getAssignedOrdersOnDate - rows.Scan: %w", err)
+		}
+		order.Address = address.entity()
+		orders = append(orders, &order)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("OrderRepo - getAssignedOrdersOnDate - rows.Err: %w", mapPgError(err))
+	}
+
+	return orders, nil
+}
+
 var _getCouriersWithGivenType = `
 	SELECT courier_id, courier_type, regions, working_hours
 	FROM couriers
-	WHERE courier_type = $1;
+	WHERE courier_type = $1
+	ORDER BY courier_id;
 `
 
 func (r *OrderRepo) getCouriersWithGivenType(ctx context.Context, courierType string) ([]*entity.CourierResponse, error) {
@@ -408,7 +1231,7 @@ func (r *OrderRepo) getCouriersWithGivenType(ctx context.Context, courierType st
 
 	rows, err := r.Pool.Query(ctx, _getCouriersWithGivenType, courierType)
 	if err != nil {
-		return nil, fmt.Errorf("OrderRepo - getCouriersWithGivenType - r.Pool.Query: %w", err)
+		return nil, fmt.Errorf("OrderRepo - getCouriersWithGivenType - r.Pool.Query: %w", mapPgError(err))
 	}
 	defer rows.Close()
 
@@ -425,82 +1248,293 @@ func (r *OrderRepo) getCouriersWithGivenType(ctx context.Context, courierType st
 	return couriers, nil
 }
 
-var _updateDistributionDateAndCourierIDInOrder = `
-	UPDATE orders
+var _getInactiveCourierIDs = `
+	SELECT courier_id FROM couriers WHERE NOT active;
+`
+
+func (r *OrderRepo) getInactiveCourierIDs(ctx context.Context) (map[uuid.UUID]struct{}, error) {
+	rows, err := r.Pool.Query(ctx, _getInactiveCourierIDs)
+	if err != nil {
+		return nil, fmt.Errorf("OrderRepo - getInactiveCourierIDs - r.Pool.Query: %w", mapPgError(err))
+	}
+	defer rows.Close()
+
+	inactive := make(map[uuid.UUID]struct{})
+	for rows.Next() {
+		var courierID uuid.UUID
+		if err := rows.Scan(&courierID); err != nil {
+			return nil, fmt.Errorf("OrderRepo - getInactiveCourierIDs - rows.Scan: %w", err)
+		}
+
+		inactive[courierID] = struct{}{}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("OrderRepo - getInactiveCourierIDs - rows.Err: %w", mapPgError(err))
+	}
+
+	return inactive, nil
+}
+
+// activeCouriers drops the inactive couriers from the list. They keep the
+// orders they already have, but don't get new ones.
+func activeCouriers(couriers []*entity.CourierResponse, inactive map[uuid.UUID]struct{}) []*entity.CourierResponse {
+	active := make([]*entity.CourierResponse, 0, len(couriers))
+	for _, courier := range couriers {
+		if _, ok := inactive[courier.CourierID]; !ok {
+			active = append(active, courier)
+		}
+	}
+
+	return active
+}
+
+// _assignOrders writes the whole result of a run in one statement, pairing
+// the order ids with the courier ids by position. Orders distributed since
+// they were read are left untouched, so the affected rows show the conflict.
+var _assignOrders = `
+	UPDATE orders o
 	SET distribution_date = $1,
-		courier_id = $2
-	WHERE order_id = $3;
+		courier_id = a.courier_id,
+		group_order_id = a.group_order_id
+	FROM unnest($2::uuid[], $3::uuid[], $4::uuid[]) AS a(order_id, courier_id, group_order_id)
+	WHERE o.order_id = a.order_id
+	AND o.distribution_date = '0001-01-01 00:00:00';
 `
 
-func (r *OrderRepo) Assign(ctx context.Context, date time.Time) ([]*entity.CourierAssignment, error) {
-	couriersAssignment := make([]*entity.CourierAssignment, 0)
+func (r *OrderRepo) Assign(ctx context.Context, date time.Time) (*entity.AssignmentRun, error) {
 	assignments := make(map[uuid.UUID]map[int]ordersGroupWithLeftTime) // mapping: courier_id -> region -> ordersGroupWithLeftTime
 
+	l := logger.FromContext(ctx).With("date", date.Format("2006-01-02"))
+
 	orders, err := r.getOrdersForAssign(ctx)
-	log.Println("len of orders for assign: ", len(orders))
 	if err != nil {
 		return nil, err
 	}
 
 	footCouriers, err := r.getCouriersWithGivenType(ctx, "FOOT")
-	log.Println("len of foot couriers for assign: ", len(footCouriers))
 	if err != nil {
 		return nil, err
 	}
 
 	bikeCouriers, err := r.getCouriersWithGivenType(ctx, "BIKE")
-	log.Println("len of bike couriers for assign: ", len(bikeCouriers))
 	if err != nil {
 		return nil, err
 	}
 
 	autoCouriers, err := r.getCouriersWithGivenType(ctx, "AUTO")
-	log.Println("len of auto couriers for assign: ", len(autoCouriers))
 	if err != nil {
 		return nil, err
 	}
 
-	sort.Slice(orders, func(i, j int) bool {
-		return orders[i].Weight > orders[j].Weight
-	})
+	inactive, err := r.getInactiveCourierIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, order := range orders {
-		if order.Weight > 20 {
-			findAndSetFreeCourier(autoCouriers, order, assignments)
-		} else if order.Weight > 10 {
-			if !findAndSetFreeCourier(autoCouriers, order, assignments) {
-				findAndSetFreeCourier(bikeCouriers, order, assignments)
-			}
-		} else {
-			if !findAndSetFreeCourier(autoCouriers, order, assignments) {
-				if !findAndSetFreeCourier(bikeCouriers, order, assignments) {
-					findAndSetFreeCourier(footCouriers, order, assignments)
+	adjacency, err := getRegionAdjacency(ctx, r.Pool)
+	if err != nil {
+		return nil, fmt.Errorf("OrderRepo - Assign - getRegionAdjacency: %w", err)
+	}
+
+	l.With("orders", len(orders)).With("foot_couriers", len(footCouriers)).With("bike_couriers", len(bikeCouriers)).With("auto_couriers", len(autoCouriers)).
+		Debug("OrderRepo - Assign - loaded orders and couriers")
+
+	couriers := make(map[uuid.UUID]*entity.CourierResponse)
+	for _, courierList := range [][]*entity.CourierResponse{footCouriers, bikeCouriers, autoCouriers} {
+		for _, courier := range courierList {
+			couriers[courier.CourierID] = courier
+		}
+	}
+
+	// Orders that are already planned for the date keep their couriers and
+	// groups, new orders only fill the capacity that's left
+	assignedOrders, err := r.getAssignedOrdersOnDate(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+
+	_, distributeSpan := tracing.Start(ctx, "OrderRepo - Assign - distribute")
+
+	seeded := make(map[uuid.UUID]struct{}, len(assignedOrders))
+	for _, order := range assignedOrders {
+		seeded[order.OrderID] = struct{}{}
+	}
+	seedAssignments(couriers, assignedOrders, assignments)
+
+	unassigned := distributeOrders(l, activeCouriers(footCouriers, inactive), activeCouriers(bikeCouriers, inactive), activeCouriers(autoCouriers, inactive), orders, assignments, adjacency)
+
+	distributeSpan.SetAttributes(attribute.Int("orders", len(orders)), attribute.Int("seeded_orders", len(assignedOrders)))
+	distributeSpan.End()
+
+	groups := make(map[uuid.UUID]map[int][]entity.OrdersGroup, len(assignments))
+	courierTypes := make(map[uuid.UUID]string, len(assignments))
+	for courierID, regions := range assignments {
+		groups[courierID] = make(map[int][]entity.OrdersGroup, len(regions))
+		for region, orderGroup := range regions {
+			// The seeded groups keep their stored ids
+			for i := range orderGroup.orders {
+				if orderGroup.orders[i].GroupOrderID == uuid.Nil {
+					orderGroup.orders[i].GroupOrderID = newGroupOrderID(courierID, date, orderGroup.orders[i].Orders[0].OrderID)
 				}
 			}
+			groups[courierID][region] = orderGroup.orders
 		}
-		log.Println(assignments)
+		courierTypes[courierID] = couriers[courierID].CourierType
 	}
 
-	for courierID, regions := range assignments {
-		assignment := entity.CourierAssignment{
-			CourierID: courierID,
-			Orders:    make([]entity.OrdersGroup, 0),
+	couriersAssignment := courierAssignmentsFromGroups(groups, courierTypes, date)
+
+	var orderIDs, courierIDs, groupIDs []uuid.UUID
+	for _, courierAssignment := range couriersAssignment {
+		for _, group := range courierAssignment.Orders {
+			for _, order := range group.Orders {
+				if _, ok := seeded[order.OrderID]; !ok {
+					orderIDs = append(orderIDs, order.OrderID)
+					courierIDs = append(courierIDs, courierAssignment.CourierID)
+					groupIDs = append(groupIDs, group.GroupOrderID)
+				}
+			}
 		}
+	}
 
-		for _, orderGroup := range regions {
-			for _, orderG := range orderGroup.orders {
-				for _, order := range orderG.Orders {
-					_, err = r.Pool.Exec(ctx, _updateDistributionDateAndCourierIDInOrder, date, courierID, order.OrderID)
-					if err != nil {
-						return nil, fmt.Errorf("OrderRepo - Assign - r.Pool.Exec(_updateDistributionDateAndCourierIDInOrder): %w", err)
-					}
+	if err := r.saveAssignment(ctx, date, orderIDs, courierIDs, groupIDs, assignedOrders); err != nil {
+		return nil, err
+	}
+
+	run := &entity.AssignmentRun{Couriers: couriersAssignment, Unassigned: unassigned}
+	for _, courierAssignment := range couriersAssignment {
+		courierType := couriers[courierAssignment.CourierID].CourierType
+		for _, group := range courierAssignment.Orders {
+			for _, order := range group.Orders {
+				if _, ok := seeded[order.OrderID]; !ok {
+					run.Assigned = append(run.Assigned, entity.AssignedOrder{OrderID: order.OrderID, Region: order.Regions, CourierType: courierType})
 				}
 			}
-			assignment.Orders = append(assignment.Orders, orderGroup.orders...)
 		}
+	}
+
+	return run, nil
+}
+
+func uniqueCourierIDs(courierIDs []uuid.UUID) []uuid.UUID {
+	unique := make([]uuid.UUID, 0)
+
+	seen := make(map[uuid.UUID]struct{})
+	for _, courierID := range courierIDs {
+		if _, ok := seen[courierID]; !ok {
+			seen[courierID] = struct{}{}
+			unique = append(unique, courierID)
+		}
+	}
+
+	return unique
+}
+
+var _getCourierOrderIDsOnDate = `
+	SELECT courier_id, order_id
+	FROM orders
+	WHERE distribution_date = $1 AND courier_id = ANY($2::uuid[]);
+`
+
+// changedCouriers counts the couriers whose orders on the date differ from
+// the seeded orders the run planned around.
+func changedCouriers(ctx context.Context, q querier, date time.Time, courierIDs []uuid.UUID, seeded []*entity.Order) (int, error) {
+	planned := make(map[uuid.UUID]map[uuid.UUID]struct{}, len(courierIDs)) // mapping: courier_id -> order_id
+	for _, courierID := range courierIDs {
+		planned[courierID] = make(map[uuid.UUID]struct{})
+	}
+	for _, order := range seeded {
+		if orders, ok := planned[order.CourierID]; ok {
+			orders[order.OrderID] = struct{}{}
+		}
+	}
+
+	rows, err := q.Query(ctx, _getCourierOrderIDsOnDate, date, courierIDs)
+	if err != nil {
+		return 0, fmt.Errorf("changedCouriers - q.Query: %w", mapPgError(err))
+	}
+	defer rows.Close()
+
+	found := make(map[uuid.UUID]int, len(courierIDs))
+	changed := make(map[uuid.UUID]struct{})
+	for rows.Next() {
+		var courierID, orderID uuid.UUID
+		if err := rows.Scan(&courierID, &orderID); err != nil {
+			return 0, fmt.Errorf("changedCouriers - rows.Scan: %w", err)
+		}
+
+		if _, ok := planned[courierID][orderID]; !ok {
+			changed[courierID] = struct{}{}
+		}
+		found[courierID]++
+	}
+
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("changedCouriers - rows.Err: %w", mapPgError(err))
+	}
+
+	// The orders taken from the couriers meanwhile
+	for courierID, orders := range planned {
+		if found[courierID] != len(orders) {
+			changed[courierID] = struct{}{}
+		}
+	}
+
+	return len(changed), nil
+}
+
+// saveAssignment writes the couriers and the groups of the new orders of the
+// run in one transaction, so a run is either saved entirely or not at all.
+// The couriers of the run are locked like SetCourierID and Reassign lock them,
+// and the run fails if their orders changed since it read the seeded ones.
+// The whole run is a single UPDATE, so postgres.statement_timeout caps the
+// save of the run, not of each order: raise it before planning days that
+// don't fit in it.
+func (r *OrderRepo) saveAssignment(ctx context.Context, date time.Time, orderIDs, courierIDs, groupIDs []uuid.UUID, seeded []*entity.Order) error {
+	ctx, span := tracing.Start(ctx, "OrderRepo - Assign - save")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("orders", len(orderIDs)))
+
+	if len(orderIDs) == 0 {
+		return nil
+	}
+
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return tracing.Error(span, fmt.Errorf("OrderRepo - saveAssignment - r.Pool.Begin: %w", mapPgError(err)))
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	runCouriers := uniqueCourierIDs(courierIDs)
+	if err := lockCouriers(ctx, tx, runCouriers); err != nil {
+		return tracing.Error(span, fmt.Errorf("OrderRepo - saveAssignment - %w", err))
+	}
+
+	changed, err := changedCouriers(ctx, tx, date, runCouriers, seeded)
+	if err != nil {
+		return tracing.Error(span, fmt.Errorf("OrderRepo - saveAssignment - %w", err))
+	}
+
+	if changed > 0 {
+		return tracing.Error(span, fmt.Errorf("OrderRepo - saveAssignment - %w", entity.NewDomainError(entity.ErrConflict,
+			fmt.Sprintf("the orders of %d couriers were changed by another request, retry the assignment", changed))))
+	}
+
+	tag, err := tx.Exec(ctx, _assignOrders, date, orderIDs, courierIDs, groupIDs)
+	if err != nil {
+		return tracing.Error(span, fmt.Errorf("OrderRepo - saveAssignment - tx.Exec(_assignOrders): %w", mapPgError(err)))
+	}
+
+	if tag.RowsAffected() != int64(len(orderIDs)) {
+		return tracing.Error(span, fmt.Errorf("OrderRepo - saveAssignment - %w", entity.NewDomainError(entity.ErrConflict,
+			fmt.Sprintf("%d of %d orders were distributed by another request, retry the assignment", int64(len(orderIDs))-tag.RowsAffected(), len(orderIDs)))))
+	}
 
-		couriersAssignment = append(couriersAssignment, &assignment)
+	if err := tx.Commit(ctx); err != nil {
+		return tracing.Error(span, fmt.Errorf("OrderRepo - saveAssignment - tx.Commit: %w", mapPgError(err)))
 	}
 
-	return couriersAssignment, nil
+	return nil
 }
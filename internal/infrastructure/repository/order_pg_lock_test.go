@@ -0,0 +1,85 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/almostinf/order_delivery_service/internal/entity"
+	"github.com/almostinf/order_delivery_service/internal/infrastructure/repository"
+	"github.com/almostinf/order_delivery_service/pkg/postgres"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// insertAssignedOrder inserts an open order of the courier distributed on the
+// date and removes it with its assignment changes when the test ends.
+func insertAssignedOrder(tb testing.TB, pg *postgres.Postgres, courierID uuid.UUID, region int, date, startedTime time.Time) uuid.UUID {
+	tb.Helper()
+
+	ctx := context.Background()
+	orderID := uuid.New()
+
+	_, err := pg.Pool.Exec(ctx, `
+		INSERT INTO orders (order_id, courier_id, weight, regions, delivery_hours, cost, completed_time, distribution_date, started_time, created_at, updated_at)
+		VALUES ($1, $2, 1, $3, $4, 100, '0001-01-01', $5, $6, now(), now())`,
+		orderID, courierID, region, []string{"00:00-23:59"}, date, startedTime)
+	if err != nil {
+		tb.Fatalf("insert order: %v", err)
+	}
+
+	tb.Cleanup(func() {
+		_, _ = pg.Pool.Exec(ctx, `DELETE FROM assignment_changes WHERE order_id = $1`, orderID)
+		_, _ = pg.Pool.Exec(ctx, `DELETE FROM orders WHERE order_id = $1`, orderID)
+	})
+
+	return orderID
+}
+
+func TestUnassignDropsStartedTime(t *testing.T) {
+	t.Parallel()
+
+	pg := testPostgres(t)
+	ctx := context.Background()
+	date := time.Date(2003, time.May, 1, 0, 0, 0, 0, time.UTC)
+
+	courierID := insertCourier(t, pg, "FOOT", 9_032)
+	orderID := insertAssignedOrder(t, pg, courierID, 9_032, date, date.Add(10*time.Hour))
+
+	order, err := repository.NewOrderRepo(pg).Unassign(ctx, orderID)
+	require.NoError(t, err)
+	require.Equal(t, uuid.Nil, order.CourierID)
+
+	var (
+		courier     *uuid.UUID
+		startedTime time.Time
+	)
+	err = pg.Pool.QueryRow(ctx, `SELECT courier_id, started_time FROM orders WHERE order_id = $1`, orderID).Scan(&courier, &startedTime)
+	require.NoError(t, err)
+	require.Nil(t, courier)
+	require.True(t, startedTime.IsZero())
+}
+
+func TestCompleteRollsBackTheBatch(t *testing.T) {
+	t.Parallel()
+
+	pg := testPostgres(t)
+	ctx := context.Background()
+	date := time.Date(2003, time.May, 2, 0, 0, 0, 0, time.UTC)
+
+	courierID := insertCourier(t, pg, "FOOT", 9_032)
+	completedID := insertAssignedOrder(t, pg, courierID, 9_032, date, date)
+	failedID := insertAssignedOrder(t, pg, courierID, 9_032, date, date)
+
+	// The second order is completed on another day than it was distributed
+	_, err := repository.NewOrderRepo(pg).Complete(ctx, []entity.CompleteInfo{
+		{CourierID: courierID, OrderID: completedID, CompleteTime: date.Add(10 * time.Hour)},
+		{CourierID: courierID, OrderID: failedID, CompleteTime: date.Add(34 * time.Hour)},
+	})
+	require.ErrorIs(t, err, entity.ErrValidation)
+
+	var completedTime time.Time
+	err = pg.Pool.QueryRow(ctx, `SELECT completed_time FROM orders WHERE order_id = $1`, completedID).Scan(&completedTime)
+	require.NoError(t, err)
+	require.True(t, completedTime.IsZero())
+}
@@ -0,0 +1,171 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/almostinf/order_delivery_service/internal/entity"
+	"github.com/almostinf/order_delivery_service/internal/infrastructure/repository"
+	"github.com/almostinf/order_delivery_service/pkg/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// _saveRegion is served by no courier, so the assignment runs of the other
+// tests leave the orders of the save tests alone.
+const _saveRegion = 9_046
+
+// insertUndistributedOrders inserts n orders of the region without a courier
+// and removes them when the test ends.
+func insertUndistributedOrders(tb testing.TB, pg *postgres.Postgres, region, n int) []uuid.UUID {
+	tb.Helper()
+
+	ctx := context.Background()
+
+	rows, err := pg.Pool.Query(ctx, `
+		INSERT INTO orders (order_id, weight, regions, delivery_hours, cost, completed_time, distribution_date, created_at, updated_at)
+		SELECT gen_random_uuid(), 1, $1, ARRAY['00:00-23:59'], 100, '0001-01-01', '0001-01-01', now(), now()
+		FROM generate_series(1, $2)
+		RETURNING order_id`, region, n)
+	if err != nil {
+		tb.Fatalf("insert orders: %v", err)
+	}
+
+	orderIDs, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		tb.Fatalf("insert orders: %v", err)
+	}
+
+	tb.Cleanup(func() {
+		_, _ = pg.Pool.Exec(ctx, `DELETE FROM orders WHERE order_id = ANY($1)`, orderIDs)
+	})
+
+	return orderIDs
+}
+
+// distributedOn counts the orders distributed on the date.
+func distributedOn(tb testing.TB, pg *postgres.Postgres, date time.Time, orderIDs []uuid.UUID) int {
+	tb.Helper()
+
+	var n int
+	err := pg.Pool.QueryRow(context.Background(), `SELECT count(*) FROM orders WHERE order_id = ANY($1) AND distribution_date = $2`, orderIDs, date).Scan(&n)
+	if err != nil {
+		tb.Fatalf("count orders: %v", err)
+	}
+
+	return n
+}
+
+// sameCourier pairs every order with the courier and a group of its own.
+func sameCourier(courierID uuid.UUID, orderIDs []uuid.UUID) ([]uuid.UUID, []uuid.UUID) {
+	courierIDs := make([]uuid.UUID, len(orderIDs))
+	groupIDs := make([]uuid.UUID, len(orderIDs))
+	for i := range orderIDs {
+		courierIDs[i] = courierID
+		groupIDs[i] = uuid.New()
+	}
+
+	return courierIDs, groupIDs
+}
+
+func TestSaveAssignmentPartialConflict(t *testing.T) {
+	t.Parallel()
+
+	pg := testPostgres(t)
+	repo := repository.NewOrderRepo(pg)
+	ctx := context.Background()
+	date := time.Date(2003, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	courierID := insertCourier(t, pg, "AUTO")
+	otherCourierID := insertCourier(t, pg, "AUTO")
+	orderIDs := insertUndistributedOrders(t, pg, _saveRegion, 3)
+
+	// Another request gives one of the orders to a courier out of the run
+	_, err := pg.Pool.Exec(ctx, `UPDATE orders SET distribution_date = $1, courier_id = $2 WHERE order_id = $3`, date, otherCourierID, orderIDs[1])
+	require.NoError(t, err)
+
+	courierIDs, groupIDs := sameCourier(courierID, orderIDs)
+	err = repo.SaveAssignment(ctx, date, orderIDs, courierIDs, groupIDs, nil)
+	require.ErrorIs(t, err, entity.ErrConflict)
+	require.Contains(t, err.Error(), "1 of 3 orders were distributed by another request")
+
+	// The run is rolled back, only the order of the other request is distributed
+	require.Equal(t, 1, distributedOn(t, pg, date, orderIDs))
+}
+
+func TestSaveAssignmentCourierChanged(t *testing.T) {
+	t.Parallel()
+
+	pg := testPostgres(t)
+	repo := repository.NewOrderRepo(pg)
+	ctx := context.Background()
+	date := time.Date(2003, time.March, 4, 0, 0, 0, 0, time.UTC)
+
+	courierID := insertCourier(t, pg, "AUTO")
+	orderIDs := insertUndistributedOrders(t, pg, _saveRegion, 3)
+
+	// The run planned around the first order of the courier
+	_, err := pg.Pool.Exec(ctx, `UPDATE orders SET distribution_date = $1, courier_id = $2 WHERE order_id = $3`, date, courierID, orderIDs[0])
+	require.NoError(t, err)
+	seeded := []*entity.Order{{OrderResponse: entity.OrderResponse{OrderID: orderIDs[0], CourierID: courierID}, DistributionDate: date}}
+
+	// A manual assignment gives the courier one more order before the save
+	_, err = pg.Pool.Exec(ctx, `UPDATE orders SET distribution_date = $1, courier_id = $2 WHERE order_id = $3`, date, courierID, orderIDs[1])
+	require.NoError(t, err)
+
+	courierIDs, groupIDs := sameCourier(courierID, orderIDs[2:])
+	err = repo.SaveAssignment(ctx, date, orderIDs[2:], courierIDs, groupIDs, seeded)
+	require.ErrorIs(t, err, entity.ErrConflict)
+	require.Contains(t, err.Error(), "the orders of 1 couriers were changed by another request")
+
+	require.Equal(t, 2, distributedOn(t, pg, date, orderIDs))
+}
+
+func TestSaveAssignmentManyOrders(t *testing.T) {
+	t.Parallel()
+
+	pg := testPostgres(t)
+	repo := repository.NewOrderRepo(pg)
+	date := time.Date(2003, time.March, 2, 0, 0, 0, 0, time.UTC)
+
+	courierID := insertCourier(t, pg, "AUTO")
+	orderIDs := insertUndistributedOrders(t, pg, _saveRegion, 30_000)
+
+	courierIDs, groupIDs := sameCourier(courierID, orderIDs)
+	require.NoError(t, repo.SaveAssignment(context.Background(), date, orderIDs, courierIDs, groupIDs, nil))
+	require.Equal(t, len(orderIDs), distributedOn(t, pg, date, orderIDs))
+}
+
+// BenchmarkSaveAssignment needs a Postgres at PG_URL. Each size is one
+// UPDATE, whatever the number of orders of the run.
+func BenchmarkSaveAssignment(b *testing.B) {
+	pg := testPostgres(b)
+	repo := repository.NewOrderRepo(pg)
+	ctx := context.Background()
+	date := time.Date(2003, time.March, 3, 0, 0, 0, 0, time.UTC)
+
+	courierID := insertCourier(b, pg, "AUTO")
+
+	for _, size := range []int{1_000, 10_000, 50_000} {
+		orderIDs := insertUndistributedOrders(b, pg, _saveRegion, size)
+		courierIDs, groupIDs := sameCourier(courierID, orderIDs)
+
+		b.Run(fmt.Sprintf("orders=%d", size), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				b.StopTimer()
+				_, err := pg.Pool.Exec(ctx, `UPDATE orders SET distribution_date = '0001-01-01', courier_id = NULL WHERE order_id = ANY($1)`, orderIDs)
+				if err != nil {
+					b.Fatalf("reset orders: %v", err)
+				}
+				b.StartTimer()
+
+				if err := repo.SaveAssignment(ctx, date, orderIDs, courierIDs, groupIDs, nil); err != nil {
+					b.Fatalf("SaveAssignment: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,669 @@
+package repository
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/almostinf/order_delivery_service/internal/entity"
+	"github.com/almostinf/order_delivery_service/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func testCouriers(courierType string, n int) []*entity.CourierResponse {
+	couriers := make([]*entity.CourierResponse, 0, n)
+	for i := 0; i < n; i++ {
+		couriers = append(couriers, &entity.CourierResponse{
+			CourierID:    uuid.NewSHA1(uuid.Nil, []byte(courierType+string(rune('a'+i)))),
+			CourierType:  courierType,
+			Regions:      []int{1, 2, 3},
+			WorkingHours: []string{"08:00-20:00"},
+		})
+	}
+
+	return couriers
+}
+
+func testOrders(n int) []*entity.OrderResponse {
+	deliveryHours := []string{"09:00-11:00", "12:00-14:00", "15:00-18:00"}
+
+	orders := make([]*entity.OrderResponse, 0, n)
+	for i := 0; i < n; i++ {
+		orders = append(orders, &entity.OrderResponse{
+			OrderID:       uuid.NewSHA1(uuid.Nil, []byte{byte(i)}),
+			Weight:        float32(i%4*5 + 1), // equal weights on purpose
+			Regions:       i%3 + 1,
+			DeliveryHours: []string{deliveryHours[i%len(deliveryHours)]},
+			Cost:          100,
+		})
+	}
+
+	return orders
+}
+
+func runDistribution(orders []*entity.OrderResponse, date time.Time) []*entity.CourierAssignment {
+	assignments := make(map[uuid.UUID]map[int]ordersGroupWithLeftTime)
+	foot, bike, auto := testCouriers("FOOT", 2), testCouriers("BIKE", 2), testCouriers("AUTO", 1)
+	distributeOrders(logger.FromContext(context.Background()), foot, bike, auto, orders, assignments, nil)
+
+	courierTypes := make(map[uuid.UUID]string)
+	for _, courier := range append(append(foot, bike...), auto...) {
+		courierTypes[courier.CourierID] = courier.CourierType
+	}
+
+	groups := make(map[uuid.UUID]map[int][]entity.OrdersGroup, len(assignments))
+	for courierID, regions := range assignments {
+		groups[courierID] = make(map[int][]entity.OrdersGroup, len(regions))
+		for region, orderGroup := range regions {
+			groups[courierID][region] = orderGroup.orders
+		}
+	}
+
+	return courierAssignmentsFromGroups(groups, courierTypes, date)
+}
+
+func TestDistributionIsDeterministic(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2023, time.April, 1, 0, 0, 0, 0, time.UTC)
+	expected := runDistribution(testOrders(40), date)
+	require.NotEmpty(t, expected)
+
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		orders := testOrders(40)
+		rnd.Shuffle(len(orders), func(i, j int) { orders[i], orders[j] = orders[j], orders[i] })
+
+		require.Equal(t, expected, runDistribution(orders, date))
+	}
+}
+
+func TestCourierAssignmentsFromGroupsOrdering(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2023, time.April, 1, 0, 0, 0, 0, time.UTC)
+	first, second := uuid.MustParse("00000000-0000-0000-0000-000000000001"), uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	orderA, orderB, orderC := uuid.MustParse("00000000-0000-0000-0000-00000000000a"), uuid.MustParse("00000000-0000-0000-0000-00000000000b"), uuid.MustParse("00000000-0000-0000-0000-00000000000c")
+
+	afternoon, noon, morning := uuid.New(), uuid.New(), uuid.New()
+
+	groups := map[uuid.UUID]map[int][]entity.OrdersGroup{
+		second: {1: {{GroupOrderID: uuid.New(), Orders: []entity.OrderResponse{{OrderID: orderC, DeliveryHours: []string{"10:00-11:00"}}}}}},
+		first: {
+			2: {{GroupOrderID: morning, Orders: []entity.OrderResponse{{OrderID: orderC, DeliveryHours: []string{"08:00-09:00"}}}}},
+			1: {
+				{GroupOrderID: afternoon, Orders: []entity.OrderResponse{
+					{OrderID: orderB, DeliveryHours: []string{"14:00-15:00"}},
+					{OrderID: orderA, DeliveryHours: []string{"14:00-15:00"}},
+				}},
+				{GroupOrderID: noon, Orders: []entity.OrderResponse{{OrderID: orderC, DeliveryHours: []string{"12:00-13:00"}}}},
+			},
+		},
+	}
+
+	result := courierAssignmentsFromGroups(groups, nil, date)
+
+	require.Len(t, result, 2)
+	require.Equal(t, first, result[0].CourierID)
+	require.Equal(t, second, result[1].CourierID)
+
+	// region 1 by delivery window, then region 2
+	require.Len(t, result[0].Orders, 3)
+	require.Equal(t, "12:00", result[0].Orders[0].DeliveryStart())
+	require.Equal(t, "14:00", result[0].Orders[1].DeliveryStart())
+	require.Equal(t, "08:00", result[0].Orders[2].DeliveryStart())
+	require.Equal(t, noon, result[0].Orders[0].GroupOrderID)
+	require.Equal(t, afternoon, result[0].Orders[1].GroupOrderID)
+	require.Equal(t, morning, result[0].Orders[2].GroupOrderID)
+
+	// equal windows fall back to the order ids
+	require.Equal(t, orderA, result[0].Orders[1].Orders[0].OrderID)
+	require.Equal(t, orderB, result[0].Orders[1].Orders[1].OrderID)
+}
+
+func TestSortOrdersGroupsKeepsGroupsInPlace(t *testing.T) {
+	t.Parallel()
+
+	lowGroup, highGroup := uuid.MustParse("00000000-0000-0000-0000-000000000001"), uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	orderA, orderB, orderC := uuid.MustParse("00000000-0000-0000-0000-00000000000a"), uuid.MustParse("00000000-0000-0000-0000-00000000000b"), uuid.MustParse("00000000-0000-0000-0000-00000000000c")
+
+	groups := []entity.OrdersGroup{
+		{GroupOrderID: highGroup, Orders: []entity.OrderResponse{{OrderID: orderB, DeliveryHours: []string{"10:00-11:00"}}}},
+		{GroupOrderID: lowGroup, Orders: []entity.OrderResponse{{OrderID: orderC, DeliveryHours: []string{"10:00-11:00"}}}},
+	}
+
+	sortOrdersGroups(groups)
+	require.Equal(t, lowGroup, groups[0].GroupOrderID)
+
+	// A new order with a lower id doesn't move its group ahead of the other
+	groups[1].Orders = append(groups[1].Orders, entity.OrderResponse{OrderID: orderA, DeliveryHours: []string{"10:00-11:00"}})
+
+	sortOrdersGroups(groups)
+	require.Equal(t, lowGroup, groups[0].GroupOrderID)
+	require.Equal(t, highGroup, groups[1].GroupOrderID)
+}
+
+func TestDistributionPrefersAdjacentRegions(t *testing.T) {
+	t.Parallel()
+
+	couriers := testCouriers("AUTO", 2)
+	orders := []*entity.OrderResponse{
+		{OrderID: uuid.New(), Weight: 30, Regions: 1, DeliveryHours: []string{"09:00-11:00"}},
+		{OrderID: uuid.New(), Weight: 25, Regions: 3, DeliveryHours: []string{"09:00-11:00"}},
+	}
+
+	testcases := []struct {
+		name              string
+		adjacency         regionAdjacency
+		expectedCourierID uuid.UUID
+	}{
+		{
+			name:              "no neighbors in the catalog",
+			expectedCourierID: couriers[0].CourierID,
+		},
+		{
+			name:              "region doesn't border the courier region",
+			adjacency:         regionAdjacency{1: {2: {}}, 2: {1: {}}},
+			expectedCourierID: couriers[1].CourierID,
+		},
+		{
+			name:              "region borders the courier region",
+			adjacency:         regionAdjacency{1: {3: {}}, 3: {1: {}}},
+			expectedCourierID: couriers[0].CourierID,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assignments := make(map[uuid.UUID]map[int]ordersGroupWithLeftTime)
+			unassigned := distributeOrders(logger.FromContext(context.Background()), nil, nil, couriers, append([]*entity.OrderResponse(nil), orders...), assignments, tc.adjacency)
+
+			require.Empty(t, unassigned)
+			require.Contains(t, assignments[tc.expectedCourierID], 3)
+		})
+	}
+}
+
+func TestParseTimeRange(t *testing.T) {
+	t.Parallel()
+
+	tr := parseTimeRange("09:30-18:05")
+
+	require.Equal(t, time.Date(0, time.January, 1, 9, 30, 0, 0, time.UTC), tr.start)
+	require.Equal(t, time.Date(0, time.January, 1, 18, 5, 0, 0, time.UTC), tr.end)
+}
+
+func TestDeliveryStatus(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2023, time.April, 1, 0, 0, 0, 0, time.UTC)
+	deliveryHours := []string{"10:00-12:00", "15:00-16:00"}
+
+	testcases := []struct {
+		name    string
+		at      time.Time
+		inRange bool
+		early   bool
+		status  string
+	}{
+		{name: "before every window", at: day.Add(9 * time.Hour), early: true, status: entity.DeliveryEarly},
+		{name: "window start", at: day.Add(10 * time.Hour), inRange: true, status: entity.DeliveryOnTime},
+		{name: "window end", at: day.Add(12 * time.Hour), inRange: true, status: entity.DeliveryOnTime},
+		{name: "between windows", at: day.Add(13 * time.Hour), status: entity.DeliveryLate},
+		{name: "second window", at: day.Add(15*time.Hour + 30*time.Minute), inRange: true, status: entity.DeliveryOnTime},
+		{name: "after every window", at: day.Add(16*time.Hour + time.Second), status: entity.DeliveryLate},
+		{name: "other date, same clock", at: day.AddDate(0, 1, 3).Add(11 * time.Hour), inRange: true, status: entity.DeliveryOnTime},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.inRange, inTimeRanges(tc.at, deliveryHours))
+			require.Equal(t, tc.early, isEarlyDelivery(tc.at, deliveryHours))
+			require.Equal(t, tc.status, deliveryStatus(tc.at, deliveryHours))
+		})
+	}
+}
+
+func TestAssignmentsKeepStoredGroups(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2023, time.April, 1, 0, 0, 0, 0, time.UTC)
+	courierID := uuid.New()
+	light, heavy := uuid.New(), uuid.New()
+
+	// The stored groups don't follow the weights, as the assignment made
+	// them with the time and capacity rules
+	order := func(weight float32, groupID uuid.UUID) *entity.Order {
+		return &entity.Order{
+			OrderResponse:    entity.OrderResponse{OrderID: uuid.New(), CourierID: courierID, Weight: weight, Regions: 1, DeliveryHours: []string{"10:00-12:00"}},
+			DistributionDate: date,
+			GroupOrderID:     groupID,
+		}
+	}
+	orders := []*entity.Order{order(30, heavy), order(20, light), order(10, heavy), order(5, light)}
+	legacy := order(1, uuid.Nil)
+
+	groupsOf := func(orders []*entity.Order) map[uuid.UUID][]uuid.UUID {
+		assignments := make(map[uuid.UUID]map[int][]entity.OrdersGroup)
+		for _, order := range orders {
+			setOrderInAssignments(assignments, order)
+		}
+
+		groups := make(map[uuid.UUID][]uuid.UUID)
+		for _, assignment := range courierAssignmentsFromGroups(assignments, map[uuid.UUID]string{courierID: "AUTO"}, date) {
+			for _, group := range assignment.Orders {
+				for _, order := range group.Orders {
+					groups[group.GroupOrderID] = append(groups[group.GroupOrderID], order.OrderID)
+				}
+			}
+		}
+
+		return groups
+	}
+
+	groups := groupsOf(append(orders, legacy))
+	require.Len(t, groups, 3)
+	require.ElementsMatch(t, []uuid.UUID{orders[0].OrderID, orders[2].OrderID}, groups[heavy])
+	require.ElementsMatch(t, []uuid.UUID{orders[1].OrderID, orders[3].OrderID}, groups[light])
+	require.Equal(t, []uuid.UUID{legacy.OrderID}, groups[newGroupOrderID(courierID, date, legacy.OrderID)])
+
+	// Unassigning an order doesn't move the others between groups
+	groups = groupsOf([]*entity.Order{orders[1], orders[2], orders[3]})
+	require.Equal(t, []uuid.UUID{orders[2].OrderID}, groups[heavy])
+	require.ElementsMatch(t, []uuid.UUID{orders[1].OrderID, orders[3].OrderID}, groups[light])
+}
+
+func testOrder(weight float32, region int, deliveryHours string, groupID uuid.UUID) *entity.Order {
+	return &entity.Order{
+		OrderResponse: entity.OrderResponse{OrderID: uuid.New(), Weight: weight, Regions: region, DeliveryHours: []string{deliveryHours}},
+		GroupOrderID:  groupID,
+	}
+}
+
+func TestCheckCourierCanTakeOrder(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2023, time.April, 1, 0, 0, 0, 0, time.UTC)
+	foot := &entity.CourierResponse{CourierID: uuid.New(), CourierType: "FOOT", Regions: []int{1, 2}, WorkingHours: []string{"10:00-11:00"}}
+
+	testcases := []struct {
+		name         string
+		active       bool
+		order        *entity.Order
+		ordersOnDate []*entity.Order
+		reasons      []string
+	}{
+		{
+			name:   "free courier",
+			active: true,
+			order:  testOrder(5, 1, "10:00-11:00", uuid.New()),
+		},
+		{
+			name:         "room left in the region",
+			active:       true,
+			order:        testOrder(5, 1, "10:00-11:00", uuid.New()),
+			ordersOnDate: []*entity.Order{testOrder(10, 1, "10:00-11:00", uuid.New())},
+		},
+		{
+			name:    "inactive courier",
+			order:   testOrder(5, 1, "10:00-11:00", uuid.New()),
+			reasons: []string{"courier is not active"},
+		},
+		{
+			name:    "region not served",
+			active:  true,
+			order:   testOrder(5, 3, "10:00-11:00", uuid.New()),
+			reasons: []string{"courier doesn't serve region 3"},
+		},
+		{
+			name:    "too heavy",
+			active:  true,
+			order:   testOrder(11, 1, "10:00-11:00", uuid.New()),
+			reasons: []string{"weight 11 exceeds the FOOT courier limit of 10"},
+		},
+		{
+			name:    "outside working hours",
+			active:  true,
+			order:   testOrder(5, 1, "10:40-12:00", uuid.New()),
+			reasons: []string{"courier working hours don't overlap the delivery hours by at least 25m0s"},
+		},
+		{
+			name:         "region overflow",
+			active:       true,
+			order:        testOrder(5, 2, "10:00-11:00", uuid.New()),
+			ordersOnDate: []*entity.Order{testOrder(5, 1, "10:00-11:00", uuid.New())},
+			reasons:      []string{"courier would deliver to 2 regions on 2023-04-01, the FOOT courier limit is 1"},
+		},
+		{
+			name:   "no time left in the region",
+			active: true,
+			order:  testOrder(5, 1, "10:00-11:00", uuid.New()),
+			ordersOnDate: []*entity.Order{
+				testOrder(5, 1, "10:00-11:00", uuid.New()),
+				testOrder(5, 1, "10:00-11:00", uuid.New()),
+			},
+			reasons: []string{"region 1: the groups take 75 minutes, the courier works 60"},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			reasons := checkCourierCanTakeOrders(foot, tc.active, []*entity.Order{tc.order}, tc.ordersOnDate, date)
+
+			require.Len(t, reasons, len(tc.reasons), reasons)
+			for i, reason := range tc.reasons {
+				require.Contains(t, reasons[i], reason)
+			}
+		})
+	}
+}
+
+func TestCheckCourierCanTakeMovedGroups(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2023, time.April, 1, 0, 0, 0, 0, time.UTC)
+	bike := &entity.CourierResponse{CourierID: uuid.New(), CourierType: "BIKE", Regions: []int{1, 2, 3}, WorkingHours: []string{"09:00-12:00"}}
+
+	group := func(n int, weight float32, region int) []*entity.Order {
+		groupID := uuid.New()
+
+		orders := make([]*entity.Order, 0, n)
+		for i := 0; i < n; i++ {
+			orders = append(orders, testOrder(weight, region, "10:00-11:00", groupID))
+		}
+
+		return orders
+	}
+
+	testcases := []struct {
+		name         string
+		active       bool
+		orders       []*entity.Order
+		ordersOnDate []*entity.Order
+		reasons      []string
+	}{
+		{
+			name:         "groups fit",
+			active:       true,
+			orders:       append(group(4, 5, 1), group(2, 5, 2)...),
+			ordersOnDate: group(3, 5, 1),
+		},
+		{
+			name:    "inactive courier",
+			orders:  group(2, 5, 1),
+			reasons: []string{"courier is not active"},
+		},
+		{
+			name:    "too many orders in a group",
+			active:  true,
+			orders:  group(5, 1, 1),
+			reasons: []string{"5 orders exceed the BIKE courier limit of 4"},
+		},
+		{
+			name:    "group too heavy",
+			active:  true,
+			orders:  group(3, 8, 1),
+			reasons: []string{"weight 24 exceeds the BIKE courier limit of 20"},
+		},
+		{
+			name:         "region overflow",
+			active:       true,
+			orders:       append(group(1, 5, 2), group(1, 5, 3)...),
+			ordersOnDate: group(1, 5, 1),
+			reasons:      []string{"courier would deliver to 3 regions on 2023-04-01, the BIKE courier limit is 2"},
+		},
+		{
+			name:   "working hours",
+			active: true,
+			orders: []*entity.Order{testOrder(5, 1, "12:00-13:00", uuid.New())},
+			reasons: []string{
+				"courier working hours don't overlap the delivery hours by at least 12m0s",
+			},
+		},
+		{
+			name:         "no time left in the region",
+			active:       true,
+			orders:       append(append(group(4, 1, 1), group(4, 1, 1)...), group(4, 1, 1)...),
+			ordersOnDate: append(append(group(4, 1, 1), group(4, 1, 1)...), group(4, 1, 1)...),
+			reasons:      []string{"region 1: the groups take 216 minutes, the courier works 180"},
+		},
+		{
+			name:         "full groups of the courier aren't reported",
+			active:       true,
+			orders:       group(1, 5, 1),
+			ordersOnDate: group(6, 5, 1),
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			reasons := checkCourierCanTakeOrders(bike, tc.active, tc.orders, tc.ordersOnDate, date)
+
+			require.Len(t, reasons, len(tc.reasons), reasons)
+			for i, reason := range tc.reasons {
+				require.Contains(t, reasons[i], reason)
+			}
+		})
+	}
+}
+
+func TestNullableCourierID(t *testing.T) {
+	t.Parallel()
+
+	require.Nil(t, nullableCourierID(uuid.Nil))
+
+	courierID := uuid.New()
+	require.Equal(t, &courierID, nullableCourierID(courierID))
+}
+
+func TestWorkingWindowMinutes(t *testing.T) {
+	t.Parallel()
+
+	courier := &entity.CourierResponse{CourierType: "FOOT", WorkingHours: []string{"08:00-09:00", "12:00-16:00"}}
+
+	testcases := []struct {
+		name          string
+		deliveryHours []string
+		minutes       int
+	}{
+		{name: "first interval", deliveryHours: []string{"08:00-08:30"}, minutes: 60},
+		{name: "second interval", deliveryHours: []string{"13:00-14:00"}, minutes: 240},
+		{name: "first overlapping interval wins", deliveryHours: []string{"15:00-16:00", "08:00-09:00"}, minutes: 60},
+		{name: "overlap too short", deliveryHours: []string{"08:45-10:00"}, minutes: 0},
+		{name: "no overlap", deliveryHours: []string{"10:00-11:00"}, minutes: 0},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			order := &entity.OrderResponse{DeliveryHours: tc.deliveryHours}
+			require.Equal(t, tc.minutes, workingWindowMinutes(courier, order, getCourierLimits("FOOT").overlap))
+		})
+	}
+}
+
+func TestSeedAssignmentsTakesCapacity(t *testing.T) {
+	t.Parallel()
+
+	l := logger.FromContext(context.Background())
+	courier := &entity.CourierResponse{CourierID: uuid.New(), CourierType: "FOOT", Regions: []int{1}, WorkingHours: []string{"10:00-11:00"}}
+	couriers := map[uuid.UUID]*entity.CourierResponse{courier.CourierID: courier}
+
+	groupID := uuid.New()
+	seeded := []*entity.Order{testOrder(2, 1, "10:00-11:00", groupID), testOrder(1, 1, "10:00-11:00", groupID)}
+	for _, order := range seeded {
+		order.CourierID = courier.CourierID
+	}
+
+	newOrder := func() []*entity.OrderResponse {
+		return []*entity.OrderResponse{{OrderID: uuid.New(), Weight: 1, Regions: 1, DeliveryHours: []string{"10:00-11:00"}}}
+	}
+
+	// Without the seeded orders the courier has the time for a group
+	assignments := make(map[uuid.UUID]map[int]ordersGroupWithLeftTime)
+	require.Empty(t, distributeOrders(l, []*entity.CourierResponse{courier}, nil, nil, newOrder(), assignments, nil))
+
+	assignments = make(map[uuid.UUID]map[int]ordersGroupWithLeftTime)
+	seedAssignments(couriers, seeded, assignments)
+
+	region := assignments[courier.CourierID][1]
+	require.Len(t, region.orders, 1)
+	require.Equal(t, groupID, region.orders[0].GroupOrderID)
+	require.Len(t, region.orders[0].Orders, 2)
+	require.Equal(t, 60-25-10, region.leftTime)
+
+	// The seeded group is full and the time left doesn't fit another one
+	require.Equal(t, map[int]int{1: 1}, distributeOrders(l, []*entity.CourierResponse{courier}, nil, nil, newOrder(), assignments, nil))
+}
+
+func TestSeedAssignmentsKeepsSeededOrders(t *testing.T) {
+	t.Parallel()
+
+	l := logger.FromContext(context.Background())
+	date := time.Date(2023, time.April, 1, 0, 0, 0, 0, time.UTC)
+	foot, bike, auto := testCouriers("FOOT", 2), testCouriers("BIKE", 2), testCouriers("AUTO", 1)
+
+	couriers := make(map[uuid.UUID]*entity.CourierResponse)
+	for _, courier := range append(append(foot, bike...), auto...) {
+		couriers[courier.CourierID] = courier
+	}
+
+	type placement struct {
+		courierID uuid.UUID
+		groupID   uuid.UUID
+	}
+
+	placements := func(assignments map[uuid.UUID]map[int]ordersGroupWithLeftTime) map[uuid.UUID]placement {
+		res := make(map[uuid.UUID]placement)
+		for courierID, regions := range assignments {
+			for _, region := range regions {
+				for _, group := range region.orders {
+					for _, order := range group.Orders {
+						res[order.OrderID] = placement{courierID, group.GroupOrderID}
+					}
+				}
+			}
+		}
+
+		return res
+	}
+
+	first := make(map[uuid.UUID]map[int]ordersGroupWithLeftTime)
+	distributeOrders(l, foot, bike, auto, testOrders(30), first, nil)
+
+	seeded := make([]*entity.Order, 0)
+	for courierID, regions := range first {
+		for _, region := range regions {
+			for _, group := range region.orders {
+				groupID := newGroupOrderID(courierID, date, group.Orders[0].OrderID)
+				for _, order := range group.Orders {
+					order.CourierID = courierID
+					seeded = append(seeded, &entity.Order{OrderResponse: order, DistributionDate: date, GroupOrderID: groupID})
+				}
+			}
+		}
+	}
+	require.NotEmpty(t, seeded)
+
+	// A second run over the seeded orders only places the new ones
+	newOrders := testOrders(60)[30:]
+	second := make(map[uuid.UUID]map[int]ordersGroupWithLeftTime)
+	seedAssignments(couriers, seeded, second)
+	distributeOrders(l, foot, bike, auto, newOrders, second, nil)
+
+	// The new orders may fill the seeded groups, but the seeded orders stay
+	// where they are
+	rerun := placements(second)
+	for _, order := range seeded {
+		require.Equal(t, placement{order.CourierID, order.GroupOrderID}, rerun[order.OrderID], order.OrderID)
+	}
+}
+
+func TestSeedAssignmentsKeepStartedTime(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2023, time.April, 1, 0, 0, 0, 0, time.UTC)
+	courier := testCouriers("BIKE", 1)[0]
+	groupID := uuid.New()
+	startedTime := date.Add(9*time.Hour + 30*time.Minute)
+
+	seeded := make([]*entity.Order, 0)
+	for _, order := range testOrders(2) {
+		order.CourierID, order.Regions = courier.CourierID, 1
+		seeded = append(seeded, &entity.Order{OrderResponse: *order, DistributionDate: date, GroupOrderID: groupID})
+	}
+
+	// The group started with its second order, the route runs from that time
+	seeded[1].StartedTime = startedTime
+
+	assignments := make(map[uuid.UUID]map[int]ordersGroupWithLeftTime)
+	seedAssignments(map[uuid.UUID]*entity.CourierResponse{courier.CourierID: courier}, seeded, assignments)
+
+	groups := assignments[courier.CourierID][1].orders
+	require.Len(t, groups, 1)
+	require.Len(t, groups[0].Orders, 2)
+	require.Equal(t, startedTime, groups[0].StartedTime)
+}
+
+func TestDistributionKeepsCourierLimits(t *testing.T) {
+	t.Parallel()
+
+	l := logger.FromContext(context.Background())
+	foot, bike, auto := testCouriers("FOOT", 2), testCouriers("BIKE", 2), testCouriers("AUTO", 1)
+	orders := testOrders(120)
+
+	couriers := make(map[uuid.UUID]*entity.CourierResponse)
+	for _, courier := range append(append(foot, bike...), auto...) {
+		couriers[courier.CourierID] = courier
+	}
+
+	assignments := make(map[uuid.UUID]map[int]ordersGroupWithLeftTime)
+	unassigned := distributeOrders(l, foot, bike, auto, orders, assignments, nil)
+
+	distributed := 0
+	for courierID, regions := range assignments {
+		limits := getCourierLimits(couriers[courierID].CourierType)
+
+		// A courier gets at most maxRegions regions
+		require.LessOrEqual(t, len(regions), limits.maxRegions, courierID)
+
+		for _, region := range regions {
+			for _, group := range region.orders {
+				require.LessOrEqual(t, len(group.Orders), limits.maxCount, courierID)
+				if len(group.Orders) > 1 {
+					require.LessOrEqual(t, groupWeight(group), limits.maxWeight, courierID)
+				}
+				distributed += len(group.Orders)
+			}
+		}
+	}
+
+	// Every order is either in a group or counted as unassigned, so starting
+	// a group doesn't drop the earlier groups of the region and filling a
+	// group doesn't drop orders
+	for _, n := range unassigned {
+		distributed += n
+	}
+	require.Equal(t, len(orders), distributed)
+}
@@ -0,0 +1,79 @@
+package repository_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/almostinf/order_delivery_service/internal/app"
+	"github.com/almostinf/order_delivery_service/pkg/logger"
+	"github.com/almostinf/order_delivery_service/pkg/postgres"
+	"github.com/google/uuid"
+)
+
+// testPostgres connects to the migrated Postgres at PG_URL and skips the
+// test without one. The tests only touch the rows they insert.
+func testPostgres(tb testing.TB, opts ...postgres.Option) *postgres.Postgres {
+	tb.Helper()
+
+	url := os.Getenv("PG_URL")
+	if url == "" {
+		tb.Skip("PG_URL is not set")
+	}
+
+	if err := app.Migrate(logger.New("error"), url, "up"); err != nil {
+		tb.Fatalf("migrate: %v", err)
+	}
+
+	pg, err := postgres.New(url, append([]postgres.Option{postgres.MaxPoolSize(4)}, opts...)...)
+	if err != nil {
+		tb.Fatalf("postgres.New: %v", err)
+	}
+	tb.Cleanup(pg.Close)
+
+	return pg
+}
+
+// insertCourier inserts a courier working all day and removes it with its
+// orders when the test ends.
+func insertCourier(tb testing.TB, pg *postgres.Postgres, courierType string, regions ...int) uuid.UUID {
+	tb.Helper()
+
+	ctx := context.Background()
+	courierID := uuid.New()
+
+	if regions == nil {
+		regions = []int{} // the column is NOT NULL
+	}
+
+	_, err := pg.Pool.Exec(ctx, `
+		INSERT INTO couriers (courier_id, courier_type, regions, working_hours, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, now(), now())`,
+		courierID, courierType, regions, []string{"00:00-23:59"})
+	if err != nil {
+		tb.Fatalf("insert courier: %v", err)
+	}
+
+	tb.Cleanup(func() {
+		_, _ = pg.Pool.Exec(ctx, `DELETE FROM assignment_changes WHERE order_id IN (SELECT order_id FROM orders WHERE courier_id = $1)`, courierID)
+		_, _ = pg.Pool.Exec(ctx, `DELETE FROM orders WHERE courier_id = $1`, courierID)
+		_, _ = pg.Pool.Exec(ctx, `DELETE FROM couriers WHERE courier_id = $1`, courierID)
+	})
+
+	return courierID
+}
+
+// insertCompletedOrder inserts an order of the courier completed on time at
+// the given time.
+func insertCompletedOrder(tb testing.TB, pg *postgres.Postgres, courierID uuid.UUID, region, cost int, createdAt, completedTime time.Time) {
+	tb.Helper()
+
+	_, err := pg.Pool.Exec(context.Background(), `
+		INSERT INTO orders (order_id, courier_id, weight, regions, delivery_hours, cost, completed_time, on_time, distribution_date, created_at, updated_at)
+		VALUES ($1, $2, 1, $3, $4, $5, $6, TRUE, $7, $7, $7)`,
+		uuid.New(), courierID, region, []string{"00:00-23:59"}, cost, completedTime, createdAt)
+	if err != nil {
+		tb.Fatalf("insert order: %v", err)
+	}
+}
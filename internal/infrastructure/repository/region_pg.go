@@ -0,0 +1,338 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/almostinf/order_delivery_service/internal/entity"
+	"github.com/almostinf/order_delivery_service/pkg/postgres"
+	"github.com/jackc/pgx/v5"
+)
+
+type RegionRepo struct {
+	*postgres.Postgres
+}
+
+func NewRegionRepo(pg *postgres.Postgres) *RegionRepo {
+	return &RegionRepo{pg}
+}
+
+var _getAllRegions = `
+	SELECT region_id, name, polygon, neighbors
+	FROM regions
+	ORDER BY region_id;
+`
+
+func (r *RegionRepo) GetAll(ctx context.Context) ([]*entity.Region, error) {
+	regions := make([]*entity.Region, 0)
+
+	rows, err := r.Pool.Query(ctx, _getAllRegions)
+	if err != nil {
+		return nil, fmt.Errorf("RegionRepo - GetAll - r.Pool.Query: %w", mapPgError(err))
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		region := &entity.Region{}
+
+		err = rows.Scan(&region.RegionID, &region.Name, &region.Polygon, &region.Neighbors)
+		if err != nil {
+			return nil, fmt.Errorf("RegionRepo - GetAll - rows.Scan: %w", err)
+		}
+
+		regions = append(regions, region)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("RegionRepo - GetAll - rows.Err: %w", mapPgError(err))
+	}
+
+	return regions, nil
+}
+
+var _getRegion = `
+	SELECT region_id, name, polygon, neighbors
+	FROM regions
+	WHERE region_id = $1;
+`
+
+func (r *RegionRepo) Get(ctx context.Context, id int) (*entity.Region, error) {
+	var region entity.Region
+
+	err := r.Pool.QueryRow(ctx, _getRegion, id).Scan(&region.RegionID, &region.Name, &region.Polygon, &region.Neighbors)
+	if err != nil {
+		return nil, fmt.Errorf("RegionRepo - Get - r.Pool.QueryRow: %w", mapPgError(err))
+	}
+
+	return &region, nil
+}
+
+var (
+	_upsertRegion = `
+		INSERT INTO regions (region_id, name, polygon, neighbors)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (region_id) DO UPDATE
+		SET name = EXCLUDED.name,
+			polygon = EXCLUDED.polygon,
+			neighbors = EXCLUDED.neighbors,
+			updated_at = now();
+	`
+
+	// The two statements keep the neighbors symmetric: the region is dropped
+	// from the regions it no longer borders and added to the new ones
+	_removeFromFormerNeighbors = `
+		UPDATE regions
+		SET neighbors = array_remove(neighbors, $1), updated_at = now()
+		WHERE $1 = ANY(neighbors) AND region_id <> ALL($2::int[]);
+	`
+
+	_addToNeighbors = `
+		UPDATE regions
+		SET neighbors = array_append(neighbors, $1), updated_at = now()
+		WHERE region_id = ANY($2::int[]) AND NOT ($1 = ANY(neighbors));
+	`
+)
+
+// Put creates the region or replaces it and updates the neighbors of the
+// regions it borders.
+func (r *RegionRepo) Put(ctx context.Context, region *entity.Region) (*entity.Region, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("RegionRepo - Put - r.Pool.Begin: %w", mapPgError(err))
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	unknown, err := lockRegions(ctx, tx, region.Neighbors)
+	if err != nil {
+		return nil, fmt.Errorf("RegionRepo - Put - lockRegions: %w", err)
+	}
+
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("RegionRepo - Put - %w", unknownRegionsError("unknown neighbors", unknown))
+	}
+
+	if _, err := tx.Exec(ctx, _upsertRegion, region.RegionID, region.Name, region.Polygon, region.Neighbors); err != nil {
+		return nil, fmt.Errorf("RegionRepo - Put - tx.Exec(_upsertRegion): %w", mapPgError(err))
+	}
+
+	if _, err := tx.Exec(ctx, _removeFromFormerNeighbors, region.RegionID, region.Neighbors); err != nil {
+		return nil, fmt.Errorf("RegionRepo - Put - tx.Exec(_removeFromFormerNeighbors): %w", mapPgError(err))
+	}
+
+	if _, err := tx.Exec(ctx, _addToNeighbors, region.RegionID, region.Neighbors); err != nil {
+		return nil, fmt.Errorf("RegionRepo - Put - tx.Exec(_addToNeighbors): %w", mapPgError(err))
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("RegionRepo - Put - tx.Commit: %w", mapPgError(err))
+	}
+
+	return region, nil
+}
+
+var (
+	// The lock waits for the transactions that reference the region and
+	// keeps new ones from referencing it until the delete ends
+	_lockRegionForDelete = `
+		SELECT region_id FROM regions WHERE region_id = $1 FOR UPDATE;
+	`
+
+	_regionInUse = `
+		SELECT EXISTS (SELECT 1 FROM couriers WHERE $1 = ANY(regions))
+			OR EXISTS (SELECT 1 FROM orders WHERE regions = $1);
+	`
+
+	_deleteRegion = `
+		DELETE FROM regions WHERE region_id = $1;
+	`
+
+	_removeFromNeighbors = `
+		UPDATE regions
+		SET neighbors = array_remove(neighbors, $1), updated_at = now()
+		WHERE $1 = ANY(neighbors);
+	`
+)
+
+// Delete removes a region no courier or order refers to. The couriers and
+// orders are created with the regions locked, so the check can't miss one
+// that is being created.
+func (r *RegionRepo) Delete(ctx context.Context, id int) error {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("RegionRepo - Delete - r.Pool.Begin: %w", mapPgError(err))
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	var regionID int
+	if err := tx.QueryRow(ctx, _lockRegionForDelete, id).Scan(&regionID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("RegionRepo - Delete - %w", entity.NewDomainError(entity.ErrNotFound, "region not found"))
+		}
+
+		return fmt.Errorf("RegionRepo - Delete - tx.QueryRow(_lockRegionForDelete): %w", mapPgError(err))
+	}
+
+	// Read after the lock, so the couriers and orders committed meanwhile count
+	var inUse bool
+	if err := tx.QueryRow(ctx, _regionInUse, id).Scan(&inUse); err != nil {
+		return fmt.Errorf("RegionRepo - Delete - tx.QueryRow(_regionInUse): %w", mapPgError(err))
+	}
+
+	if inUse {
+		return fmt.Errorf("RegionRepo - Delete - %w", entity.NewDomainError(entity.ErrConflict, "the region is used by couriers or orders"))
+	}
+
+	if _, err := tx.Exec(ctx, _deleteRegion, id); err != nil {
+		return fmt.Errorf("RegionRepo - Delete - tx.Exec(_deleteRegion): %w", mapPgError(err))
+	}
+
+	if _, err := tx.Exec(ctx, _removeFromNeighbors, id); err != nil {
+		return fmt.Errorf("RegionRepo - Delete - tx.Exec(_removeFromNeighbors): %w", mapPgError(err))
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("RegionRepo - Delete - tx.Commit: %w", mapPgError(err))
+	}
+
+	return nil
+}
+
+// Unknown returns the ids missing from the catalog in ascending order.
+func (r *RegionRepo) Unknown(ctx context.Context, ids []int) ([]int, error) {
+	unknown, err := unknownRegions(ctx, r.Pool, ids)
+	if err != nil {
+		return nil, fmt.Errorf("RegionRepo - Unknown - unknownRegions: %w", err)
+	}
+
+	return unknown, nil
+}
+
+var _unknownRegions = `
+	SELECT array(
+		SELECT DISTINCT id
+		FROM unnest($1::int[]) AS id
+		WHERE NOT EXISTS (SELECT 1 FROM regions WHERE region_id = id)
+		ORDER BY id
+	);
+`
+
+func unknownRegions(ctx context.Context, q querier, ids []int) ([]int, error) {
+	unknown := make([]int, 0)
+	if len(ids) == 0 {
+		return unknown, nil
+	}
+
+	if err := q.QueryRow(ctx, _unknownRegions, ids).Scan(&unknown); err != nil {
+		return nil, mapPgError(err)
+	}
+
+	return unknown, nil
+}
+
+var _lockRegions = `
+	SELECT region_id
+	FROM regions
+	WHERE region_id = ANY($1::int[])
+	FOR KEY SHARE;
+`
+
+// lockRegions returns the ids missing from the catalog in ascending order like
+// unknownRegions, and locks the others until the end of the transaction, so
+// they can't be deleted while it refers to them.
+func lockRegions(ctx context.Context, q querier, ids []int) ([]int, error) {
+	unknown := make([]int, 0)
+	if len(ids) == 0 {
+		return unknown, nil
+	}
+
+	rows, err := q.Query(ctx, _lockRegions, ids)
+	if err != nil {
+		return nil, mapPgError(err)
+	}
+
+	existing, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, mapPgError(err)
+	}
+
+	seen := make(map[int]struct{}, len(ids))
+	for _, id := range existing {
+		seen[id] = struct{}{}
+	}
+
+	for _, id := range ids {
+		if _, ok := seen[id]; !ok {
+			seen[id] = struct{}{}
+			unknown = append(unknown, id)
+		}
+	}
+	sort.Ints(unknown)
+
+	return unknown, nil
+}
+
+// unknownRegionsError rejects the references to the regions missing from the
+// catalog.
+func unknownRegionsError(message string, unknown []int) *entity.DomainError {
+	details := make([]string, 0, len(unknown))
+	for _, id := range unknown {
+		details = append(details, fmt.Sprintf("region %d doesn't exist", id))
+	}
+
+	return entity.NewDomainError(entity.ErrValidation, message).WithDetails(details...)
+}
+
+var _getRegionNeighbors = `
+	SELECT region_id, neighbors
+	FROM regions
+	WHERE cardinality(neighbors) > 0;
+`
+
+// regionAdjacency tells if two regions border each other.
+type regionAdjacency map[int]map[int]struct{}
+
+func (a regionAdjacency) adjacent(first, second int) bool {
+	_, ok := a[first][second]
+	return ok
+}
+
+func getRegionAdjacency(ctx context.Context, q querier) (regionAdjacency, error) {
+	adjacency := make(regionAdjacency)
+
+	rows, err := q.Query(ctx, _getRegionNeighbors)
+	if err != nil {
+		return nil, mapPgError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			regionID  int
+			neighbors []int
+		)
+
+		if err := rows.Scan(&regionID, &neighbors); err != nil {
+			return nil, err
+		}
+
+		for _, neighbor := range neighbors {
+			if adjacency[regionID] == nil {
+				adjacency[regionID] = make(map[int]struct{})
+			}
+			if adjacency[neighbor] == nil {
+				adjacency[neighbor] = make(map[int]struct{})
+			}
+
+			adjacency[regionID][neighbor] = struct{}{}
+			adjacency[neighbor][regionID] = struct{}{}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, mapPgError(err)
+	}
+
+	return adjacency, nil
+}
@@ -0,0 +1,78 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/almostinf/order_delivery_service/internal/entity"
+	"github.com/almostinf/order_delivery_service/internal/infrastructure/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateRejectsUnknownRegions(t *testing.T) {
+	t.Parallel()
+
+	pg := testPostgres(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	courier := &entity.Courier{
+		CourierResponse: entity.CourierResponse{CourierID: uuid.New(), CourierType: "FOOT", Regions: []int{9_048_001}, WorkingHours: []string{"08:00-20:00"}},
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	_, err := repository.NewCourierRepo(pg).Create(ctx, courier)
+	require.ErrorIs(t, err, entity.ErrValidation)
+
+	order := &entity.Order{
+		OrderResponse: entity.OrderResponse{OrderID: uuid.New(), Weight: 1, Regions: 9_048_001, DeliveryHours: []string{"10:00-12:00"}, Cost: 100},
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	_, err = repository.NewOrderRepo(pg).Create(ctx, order)
+	require.ErrorIs(t, err, entity.ErrValidation)
+}
+
+func TestDeleteRegionWaitsForCreate(t *testing.T) {
+	t.Parallel()
+
+	const region = 9_048_002
+
+	pg := testPostgres(t)
+	repo := repository.NewRegionRepo(pg)
+	ctx := context.Background()
+
+	_, err := repo.Put(ctx, &entity.Region{RegionID: region, Name: "Pending", Neighbors: []int{}})
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = pg.Pool.Exec(ctx, `DELETE FROM regions WHERE region_id = $1`, region) })
+
+	// A create in flight: the region is locked and the courier isn't committed yet
+	tx, err := pg.Pool.Begin(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	_, err = tx.Exec(ctx, `SELECT region_id FROM regions WHERE region_id = $1 FOR KEY SHARE`, region)
+	require.NoError(t, err)
+
+	courierID := uuid.New()
+	_, err = tx.Exec(ctx, `
+		INSERT INTO couriers (courier_id, courier_type, regions, working_hours, created_at, updated_at)
+		VALUES ($1, 'FOOT', $2, $3, now(), now())`,
+		courierID, []int{region}, []string{"08:00-20:00"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = pg.Pool.Exec(ctx, `DELETE FROM couriers WHERE courier_id = $1`, courierID) })
+
+	deleted := make(chan error, 1)
+	go func() { deleted <- repo.Delete(ctx, region) }()
+
+	select {
+	case err := <-deleted:
+		t.Fatalf("Delete didn't wait for the create: %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	require.NoError(t, tx.Commit(ctx))
+	require.ErrorIs(t, <-deleted, entity.ErrConflict)
+}
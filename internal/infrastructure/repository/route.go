@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/almostinf/order_delivery_service/internal/entity"
+)
+
+const _earthRadiusKm = 6371.0
+
+// routeStop is an order of a group with its delivery windows as offsets
+// from the midnight of the delivery date.
+type routeStop struct {
+	order   *entity.OrderResponse
+	windows []timeWindow
+}
+
+type timeWindow struct {
+	open  time.Duration
+	close time.Duration
+}
+
+func newRouteStop(order *entity.OrderResponse) routeStop {
+	midnight := time.Date(0, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	windows := make([]timeWindow, 0, len(order.DeliveryHours))
+	for _, deliveryHours := range order.DeliveryHours {
+		tr := parseTimeRange(deliveryHours)
+		windows = append(windows, timeWindow{open: tr.start.Sub(midnight), close: tr.end.Sub(midnight)})
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i].open < windows[j].open })
+
+	return routeStop{order: order, windows: windows}
+}
+
+// serve returns when the stop reached at the arrival is delivered, the
+// courier waits for the next window when it comes early, and how late the
+// delivery is when every window is already closed.
+func (s routeStop) serve(arrival time.Duration) (time.Duration, time.Duration) {
+	for _, w := range s.windows {
+		if arrival <= w.close {
+			if arrival < w.open {
+				return w.open, 0
+			}
+			return arrival, 0
+		}
+	}
+
+	if len(s.windows) == 0 {
+		return arrival, 0
+	}
+
+	return arrival, arrival - s.windows[len(s.windows)-1].close
+}
+
+// routeCost ranks the sequences: fewer late orders first, then the total
+// lateness and then the time the last order is delivered.
+type routeCost struct {
+	late     int
+	lateness time.Duration
+	finish   time.Duration
+}
+
+func (c routeCost) less(other routeCost) bool {
+	if c.late != other.late {
+		return c.late < other.late
+	}
+
+	if c.lateness != other.lateness {
+		return c.lateness < other.lateness
+	}
+
+	return c.finish < other.finish
+}
+
+type route struct {
+	stops  []routeStop
+	limits courierLimits
+	start  time.Duration
+}
+
+// travel estimates the time between two orders. The couriers have no
+// location before the first order, and the legs with an order without an
+// address take the flat time of the courier type.
+func (r route) travel(from, to int) time.Duration {
+	if from < 0 {
+		return 0
+	}
+
+	a, b := r.stops[from].order.Address, r.stops[to].order.Address
+	if a == nil || b == nil || r.limits.speed <= 0 {
+		return time.Duration(r.limits.nextDeliveryTime) * time.Minute
+	}
+
+	hours := distanceKm(a.Latitude, a.Longitude, b.Latitude, b.Longitude) / r.limits.speed
+
+	return time.Duration(hours * float64(time.Hour)).Round(time.Minute)
+}
+
+// evaluate walks the sequence and returns its cost with the delivery time of
+// every stop.
+func (r route) evaluate(sequence []int) (routeCost, []time.Duration) {
+	var cost routeCost
+	etas := make([]time.Duration, len(sequence))
+
+	now, prev := r.start, -1
+	for i, stop := range sequence {
+		eta, lateness := r.stops[stop].serve(now + r.travel(prev, stop))
+		if lateness > 0 {
+			cost.late++
+			cost.lateness += lateness
+		}
+
+		etas[i] = eta
+		now, prev = eta, stop
+	}
+	cost.finish = now
+
+	return cost, etas
+}
+
+// nearestNeighbour builds the first sequence by going to the stop that can
+// be delivered the soonest, preferring the stops that won't be late. Ties go
+// to the lower index, so the result only depends on the order of the stops.
+func (r route) nearestNeighbour() []int {
+	sequence := make([]int, 0, len(r.stops))
+	visited := make([]bool, len(r.stops))
+
+	now, prev := r.start, -1
+	for len(sequence) < len(r.stops) {
+		best, bestETA, bestOnTime := -1, time.Duration(0), false
+
+		for i := range r.stops {
+			if visited[i] {
+				continue
+			}
+
+			eta, lateness := r.stops[i].serve(now + r.travel(prev, i))
+			onTime := lateness == 0
+
+			if best < 0 || (onTime && !bestOnTime) || (onTime == bestOnTime && eta < bestETA) {
+				best, bestETA, bestOnTime = i, eta, onTime
+			}
+		}
+
+		visited[best] = true
+		sequence = append(sequence, best)
+		now, prev = bestETA, best
+	}
+
+	return sequence
+}
+
+// twoOpt reverses the segments of the sequence while it makes the route
+// cheaper. The groups are a few orders long, so every pair is tried.
+func (r route) twoOpt(sequence []int) []int {
+	best, _ := r.evaluate(sequence)
+
+	for improved := true; improved; {
+		improved = false
+
+		for i := 0; i < len(sequence)-1; i++ {
+			for j := i + 1; j < len(sequence); j++ {
+				candidate := make([]int, len(sequence))
+				copy(candidate, sequence)
+				for a, b := i, j; a < b; a, b = a+1, b-1 {
+					candidate[a], candidate[b] = candidate[b], candidate[a]
+				}
+
+				if cost, _ := r.evaluate(candidate); cost.less(best) {
+					sequence, best, improved = candidate, cost, true
+				}
+			}
+		}
+	}
+
+	return sequence
+}
+
+// sequenceGroup sorts the orders of the group in the visiting order and sets
+// their sequence numbers and ETAs on the date. The route starts when the
+// courier started the group or at the earliest delivery window.
+func sequenceGroup(group *entity.OrdersGroup, limits courierLimits, date time.Time) {
+	if len(group.Orders) == 0 {
+		return
+	}
+
+	r := route{stops: make([]routeStop, 0, len(group.Orders)), limits: limits}
+	for i := range group.Orders {
+		r.stops = append(r.stops, newRouteStop(&group.Orders[i]))
+	}
+
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	if !group.StartedTime.IsZero() {
+		r.start = group.StartedTime.Sub(day)
+	} else {
+		r.start = -1
+		for _, stop := range r.stops {
+			if len(stop.windows) > 0 && (r.start < 0 || stop.windows[0].open < r.start) {
+				r.start = stop.windows[0].open
+			}
+		}
+		if r.start < 0 {
+			r.start = 0
+		}
+	}
+
+	sequence := r.twoOpt(r.nearestNeighbour())
+	_, etas := r.evaluate(sequence)
+
+	orders := make([]entity.OrderResponse, 0, len(sequence))
+	for i, stop := range sequence {
+		order := *r.stops[stop].order
+		order.Sequence = i + 1
+		eta := day.Add(etas[i])
+		order.ETA = &eta
+		orders = append(orders, order)
+	}
+	copy(group.Orders, orders)
+}
+
+// distanceKm is the great-circle distance between two points.
+func distanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat, dLon := toRad(lat2-lat1), toRad(lon2-lon1)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * _earthRadiusKm * math.Asin(math.Sqrt(h))
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/almostinf/order_delivery_service/internal/entity"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func routeOrder(id byte, longitude float64, deliveryHours ...string) entity.OrderResponse {
+	return entity.OrderResponse{
+		OrderID:       uuid.NewSHA1(uuid.Nil, []byte{id}),
+		DeliveryHours: deliveryHours,
+		Address:       &entity.Address{Address: "street", Latitude: 55.75, Longitude: longitude},
+	}
+}
+
+func TestSequenceGroupShortensTheRoute(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2023, time.April, 1, 0, 0, 0, 0, time.UTC)
+
+	// the orders lie on a line about 600 meters apart
+	group := entity.OrdersGroup{Orders: []entity.OrderResponse{
+		routeOrder('b', 37.61, "10:00-18:00"),
+		routeOrder('d', 37.63, "10:00-18:00"),
+		routeOrder('a', 37.60, "10:00-18:00"),
+		routeOrder('c', 37.62, "10:00-18:00"),
+	}}
+
+	sequenceGroup(&group, getCourierLimits("FOOT"), date)
+
+	longitudes := make([]float64, 0, len(group.Orders))
+	for i, order := range group.Orders {
+		require.Equal(t, i+1, order.Sequence)
+		require.NotNil(t, order.ETA)
+		longitudes = append(longitudes, order.Address.Longitude)
+	}
+
+	if longitudes[0] > longitudes[len(longitudes)-1] {
+		require.Equal(t, []float64{37.63, 37.62, 37.61, 37.60}, longitudes)
+	} else {
+		require.Equal(t, []float64{37.60, 37.61, 37.62, 37.63}, longitudes)
+	}
+
+	require.Equal(t, date.Add(10*time.Hour), *group.Orders[0].ETA)
+	require.Equal(t, 8*time.Minute, group.Orders[1].ETA.Sub(*group.Orders[0].ETA))
+}
+
+func TestSequenceGroupKeepsDeliveryWindows(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2023, time.April, 1, 0, 0, 0, 0, time.UTC)
+
+	// the nearest order to the first one opens last
+	late := routeOrder('l', 37.6001, "13:00-14:00")
+	group := entity.OrdersGroup{Orders: []entity.OrderResponse{
+		routeOrder('e', 37.60, "09:00-10:00"),
+		late,
+		routeOrder('m', 37.64, "09:00-18:00"),
+	}}
+
+	sequenceGroup(&group, getCourierLimits("BIKE"), date)
+
+	require.Equal(t, late.OrderID, group.Orders[2].OrderID)
+	for _, order := range group.Orders {
+		require.True(t, inTimeRanges(*order.ETA, order.DeliveryHours), "order %d is delivered at %s", order.Sequence, order.ETA)
+	}
+	require.Equal(t, date.Add(13*time.Hour), *group.Orders[2].ETA)
+}
+
+func TestSequenceGroupWithoutAddresses(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2023, time.April, 1, 0, 0, 0, 0, time.UTC)
+	started := date.Add(11 * time.Hour)
+
+	group := entity.OrdersGroup{
+		StartedTime: started,
+		Orders: []entity.OrderResponse{
+			{OrderID: uuid.NewSHA1(uuid.Nil, []byte{1}), DeliveryHours: []string{"10:00-18:00"}},
+			{OrderID: uuid.NewSHA1(uuid.Nil, []byte{2}), DeliveryHours: []string{"10:00-18:00"}},
+		},
+	}
+
+	sequenceGroup(&group, getCourierLimits("AUTO"), date)
+
+	require.Equal(t, started, *group.Orders[0].ETA)
+	require.Equal(t, started.Add(4*time.Minute), *group.Orders[1].ETA)
+}
@@ -0,0 +1,93 @@
+// Package metrics defines the Prometheus metrics of the service.
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const _namespace = "order_delivery"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: _namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Number of HTTP requests by route and status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: _namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of HTTP requests by route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	ordersCreated = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: _namespace,
+		Name:      "orders_created_total",
+		Help:      "Number of created orders by region.",
+	}, []string{"region"})
+
+	ordersCompleted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: _namespace,
+		Name:      "orders_completed_total",
+		Help:      "Number of completed orders by region and courier type.",
+	}, []string{"region", "courier_type"})
+
+	ordersAssigned = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: _namespace,
+		Name:      "orders_assigned_total",
+		Help:      "Number of orders assigned by the assignment runs by region and courier type.",
+	}, []string{"region", "courier_type"})
+
+	ordersUnassigned = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: _namespace,
+		Name:      "orders_unassigned",
+		Help:      "Number of orders left without a courier by the last assignment run by region.",
+	}, []string{"region"})
+
+	assignmentDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: _namespace,
+		Name:      "assignment_duration_seconds",
+		Help:      "Duration of the assignment runs.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12),
+	})
+)
+
+// ObserveHTTPRequest counts the request and its latency.
+func ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	statusStr := strconv.Itoa(status)
+
+	httpRequests.WithLabelValues(method, route, statusStr).Inc()
+	httpDuration.WithLabelValues(method, route, statusStr).Observe(duration.Seconds())
+}
+
+func OrderCreated(region int) {
+	ordersCreated.WithLabelValues(strconv.Itoa(region)).Inc()
+}
+
+func OrderCompleted(region int, courierType string) {
+	ordersCompleted.WithLabelValues(strconv.Itoa(region), courierType).Inc()
+}
+
+func OrderAssigned(region int, courierType string) {
+	ordersAssigned.WithLabelValues(strconv.Itoa(region), courierType).Inc()
+}
+
+// AssignmentRun records the duration of the run and the orders it left
+// without a courier per region. The gauge only describes the last run, whatever
+// its date: the regions the run left nothing in are dropped, not kept at their
+// value from an earlier run.
+func AssignmentRun(duration time.Duration, unassigned map[int]int) {
+	assignmentDuration.Observe(duration.Seconds())
+
+	ordersUnassigned.Reset()
+	for region, count := range unassigned {
+		ordersUnassigned.WithLabelValues(strconv.Itoa(region)).Set(float64(count))
+	}
+}
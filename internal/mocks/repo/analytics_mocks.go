@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/infrastructure/interfaces/analytics.go
+
+// Package mock_interfaces is a generated GoMock package.
+package mock_interfaces
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entity "github.com/almostinf/order_delivery_service/internal/entity"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAnalytics is a mock of Analytics interface.
+type MockAnalytics struct {
+	ctrl     *gomock.Controller
+	recorder *MockAnalyticsMockRecorder
+}
+
+// MockAnalyticsMockRecorder is the mock recorder for MockAnalytics.
+type MockAnalyticsMockRecorder struct {
+	mock *MockAnalytics
+}
+
+// NewMockAnalytics creates a new mock instance.
+func NewMockAnalytics(ctrl *gomock.Controller) *MockAnalytics {
+	mock := &MockAnalytics{ctrl: ctrl}
+	mock.recorder = &MockAnalyticsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAnalytics) EXPECT() *MockAnalyticsMockRecorder {
+	return m.recorder
+}
+
+// GetCouriersAnalytics mocks base method.
+func (m *MockAnalytics) GetCouriersAnalytics(ctx context.Context, filter entity.CourierAnalyticsFilter) ([]*entity.CourierAnalytics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCouriersAnalytics", ctx, filter)
+	ret0, _ := ret[0].([]*entity.CourierAnalytics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCouriersAnalytics indicates an expected call of GetCouriersAnalytics.
+func (mr *MockAnalyticsMockRecorder) GetCouriersAnalytics(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCouriersAnalytics", reflect.TypeOf((*MockAnalytics)(nil).GetCouriersAnalytics), ctx, filter)
+}
+
+// GetOrdersAnalytics mocks base method.
+func (m *MockAnalytics) GetOrdersAnalytics(ctx context.Context, filter entity.OrderAnalyticsFilter) ([]*entity.OrderAnalytics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrdersAnalytics", ctx, filter)
+	ret0, _ := ret[0].([]*entity.OrderAnalytics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrdersAnalytics indicates an expected call of GetOrdersAnalytics.
+func (mr *MockAnalyticsMockRecorder) GetOrdersAnalytics(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrdersAnalytics", reflect.TypeOf((*MockAnalytics)(nil).GetOrdersAnalytics), ctx, filter)
+}
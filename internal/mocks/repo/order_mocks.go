@@ -38,10 +38,10 @@ func (m *MockOrder) EXPECT() *MockOrderMockRecorder {
 }
 
 // Assign mocks base method.
-func (m *MockOrder) Assign(ctx context.Context, date time.Time) ([]*entity.CourierAssignment, error) {
+func (m *MockOrder) Assign(ctx context.Context, date time.Time) (*entity.AssignmentRun, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Assign", ctx, date)
-	ret0, _ := ret[0].([]*entity.CourierAssignment)
+	ret0, _ := ret[0].(*entity.AssignmentRun)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -53,10 +53,10 @@ func (mr *MockOrderMockRecorder) Assign(ctx, date interface{}) *gomock.Call {
 }
 
 // Complete mocks base method.
-func (m *MockOrder) Complete(ctx context.Context, completeInfoReq []entity.CompleteInfo) ([]*entity.OrderResponse, error) {
+func (m *MockOrder) Complete(ctx context.Context, completeInfoReq []entity.CompleteInfo) ([]*entity.CompletedOrder, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Complete", ctx, completeInfoReq)
-	ret0, _ := ret[0].([]*entity.OrderResponse)
+	ret0, _ := ret[0].([]*entity.CompletedOrder)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -112,6 +112,21 @@ func (mr *MockOrderMockRecorder) GetAll(ctx, limit, offset interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockOrder)(nil).GetAll), ctx, limit, offset)
 }
 
+// Reassign mocks base method.
+func (m *MockOrder) Reassign(ctx context.Context, date time.Time, fromCourierID, toCourierID uuid.UUID) (*entity.CourierAssignment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reassign", ctx, date, fromCourierID, toCourierID)
+	ret0, _ := ret[0].(*entity.CourierAssignment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reassign indicates an expected call of Reassign.
+func (mr *MockOrderMockRecorder) Reassign(ctx, date, fromCourierID, toCourierID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reassign", reflect.TypeOf((*MockOrder)(nil).Reassign), ctx, date, fromCourierID, toCourierID)
+}
+
 // SetCourierID mocks base method.
 func (m *MockOrder) SetCourierID(ctx context.Context, orderID, courierID uuid.UUID) (*entity.OrderResponse, error) {
 	m.ctrl.T.Helper()
@@ -126,3 +141,33 @@ func (mr *MockOrderMockRecorder) SetCourierID(ctx, orderID, courierID interface{
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCourierID", reflect.TypeOf((*MockOrder)(nil).SetCourierID), ctx, orderID, courierID)
 }
+
+// StartDelivery mocks base method.
+func (m *MockOrder) StartDelivery(ctx context.Context, courierID uuid.UUID, orderIDs []uuid.UUID, startTime time.Time) ([]*entity.OrderResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartDelivery", ctx, courierID, orderIDs, startTime)
+	ret0, _ := ret[0].([]*entity.OrderResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartDelivery indicates an expected call of StartDelivery.
+func (mr *MockOrderMockRecorder) StartDelivery(ctx, courierID, orderIDs, startTime interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartDelivery", reflect.TypeOf((*MockOrder)(nil).StartDelivery), ctx, courierID, orderIDs, startTime)
+}
+
+// Unassign mocks base method.
+func (m *MockOrder) Unassign(ctx context.Context, orderID uuid.UUID) (*entity.OrderResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unassign", ctx, orderID)
+	ret0, _ := ret[0].(*entity.OrderResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Unassign indicates an expected call of Unassign.
+func (mr *MockOrderMockRecorder) Unassign(ctx, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unassign", reflect.TypeOf((*MockOrder)(nil).Unassign), ctx, orderID)
+}
@@ -0,0 +1,110 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/infrastructure/interfaces/region.go
+
+// Package mock_interfaces is a generated GoMock package.
+package mock_interfaces
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entity "github.com/almostinf/order_delivery_service/internal/entity"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockRegion is a mock of Region interface.
+type MockRegion struct {
+	ctrl     *gomock.Controller
+	recorder *MockRegionMockRecorder
+}
+
+// MockRegionMockRecorder is the mock recorder for MockRegion.
+type MockRegionMockRecorder struct {
+	mock *MockRegion
+}
+
+// NewMockRegion creates a new mock instance.
+func NewMockRegion(ctrl *gomock.Controller) *MockRegion {
+	mock := &MockRegion{ctrl: ctrl}
+	mock.recorder = &MockRegionMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRegion) EXPECT() *MockRegionMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockRegion) Delete(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockRegionMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRegion)(nil).Delete), ctx, id)
+}
+
+// Get mocks base method.
+func (m *MockRegion) Get(ctx context.Context, id int) (*entity.Region, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(*entity.Region)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockRegionMockRecorder) Get(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockRegion)(nil).Get), ctx, id)
+}
+
+// GetAll mocks base method.
+func (m *MockRegion) GetAll(ctx context.Context) ([]*entity.Region, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx)
+	ret0, _ := ret[0].([]*entity.Region)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockRegionMockRecorder) GetAll(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockRegion)(nil).GetAll), ctx)
+}
+
+// Put mocks base method.
+func (m *MockRegion) Put(ctx context.Context, region *entity.Region) (*entity.Region, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Put", ctx, region)
+	ret0, _ := ret[0].(*entity.Region)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Put indicates an expected call of Put.
+func (mr *MockRegionMockRecorder) Put(ctx, region interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockRegion)(nil).Put), ctx, region)
+}
+
+// Unknown mocks base method.
+func (m *MockRegion) Unknown(ctx context.Context, ids []int) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unknown", ctx, ids)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Unknown indicates an expected call of Unknown.
+func (mr *MockRegionMockRecorder) Unknown(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unknown", reflect.TypeOf((*MockRegion)(nil).Unknown), ctx, ids)
+}
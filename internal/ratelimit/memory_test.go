@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStoreTake(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)
+	s := NewMemoryStore(time.Minute)
+	s.now = func() time.Time { return now }
+
+	rule := Rule{RPS: 1, Burst: 2}
+
+	res := take(t, s, "a", rule)
+	require.Equal(t, Result{Allowed: true, Limit: 2, Remaining: 1, Reset: time.Second}, res)
+
+	res = take(t, s, "a", rule)
+	require.Equal(t, Result{Allowed: true, Limit: 2, Remaining: 0, Reset: 2 * time.Second}, res)
+
+	res = take(t, s, "a", rule)
+	require.False(t, res.Allowed)
+	require.Equal(t, time.Second, res.RetryAfter)
+
+	// Other clients have their own buckets
+	require.True(t, take(t, s, "b", rule).Allowed)
+
+	now = now.Add(time.Second)
+	require.True(t, take(t, s, "a", rule).Allowed)
+}
+
+func TestMemoryStoreDropsIdleBuckets(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)
+	s := NewMemoryStore(time.Minute)
+	s.now = func() time.Time { return now }
+
+	take(t, s, "a", Rule{RPS: 1, Burst: 1})
+	require.Len(t, s.buckets, 1)
+
+	now = now.Add(2 * time.Minute)
+	take(t, s, "b", Rule{RPS: 1, Burst: 1})
+	require.Len(t, s.buckets, 1)
+	require.Contains(t, s.buckets, "b")
+}
+
+func take(t *testing.T, s Store, key string, rule Rule) Result {
+	t.Helper()
+
+	res, err := s.Take(context.Background(), key, rule)
+	require.NoError(t, err)
+
+	return res
+}
@@ -0,0 +1,88 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/almostinf/order_delivery_service/pkg/logger"
+	"github.com/almostinf/order_delivery_service/pkg/postgres"
+)
+
+// The bucket is refilled and taken from in one statement, so concurrent
+// requests of all replicas are serialized by the row lock. The database
+// clock is used to keep replicas with skewed clocks consistent.
+var _takeToken = `
+	WITH now AS (SELECT clock_timestamp() AS ts)
+	INSERT INTO rate_limit_buckets AS b (key, tokens, allowed, updated_at)
+	SELECT $1::text, $2::float8 - 1, TRUE, now.ts FROM now
+	ON CONFLICT (key) DO UPDATE SET
+		tokens = CASE
+			WHEN LEAST($2::float8, b.tokens + GREATEST(EXTRACT(EPOCH FROM (EXCLUDED.updated_at - b.updated_at))::float8, 0) * $3::float8) >= 1
+			THEN LEAST($2::float8, b.tokens + GREATEST(EXTRACT(EPOCH FROM (EXCLUDED.updated_at - b.updated_at))::float8, 0) * $3::float8) - 1
+			ELSE LEAST($2::float8, b.tokens + GREATEST(EXTRACT(EPOCH FROM (EXCLUDED.updated_at - b.updated_at))::float8, 0) * $3::float8)
+		END,
+		allowed = LEAST($2::float8, b.tokens + GREATEST(EXTRACT(EPOCH FROM (EXCLUDED.updated_at - b.updated_at))::float8, 0) * $3::float8) >= 1,
+		updated_at = GREATEST(b.updated_at, EXCLUDED.updated_at)
+	RETURNING tokens, allowed;
+`
+
+// The sweep compares with the database clock too, the buckets are stamped
+// with it.
+var _deleteIdleBuckets = `
+	DELETE FROM rate_limit_buckets WHERE updated_at < now() - make_interval(secs => $1);
+`
+
+// PostgresStore keeps the buckets in the rate_limit_buckets table, so the
+// limits hold across replicas. Buckets that weren't used for the idle
+// duration are deleted by Sweep in the background.
+type PostgresStore struct {
+	*postgres.Postgres
+
+	idle time.Duration
+}
+
+var _ Store = (*PostgresStore)(nil)
+
+func NewPostgresStore(pg *postgres.Postgres, idle time.Duration) *PostgresStore {
+	return &PostgresStore{Postgres: pg, idle: idle}
+}
+
+func (s *PostgresStore) Take(ctx context.Context, key string, rule Rule) (Result, error) {
+	var tokens float64
+	var allowed bool
+
+	err := s.Pool.QueryRow(ctx, _takeToken, key, float64(rule.Burst), rule.RPS).Scan(&tokens, &allowed)
+	if err != nil {
+		return Result{}, fmt.Errorf("PostgresStore - Take - s.Pool.QueryRow: %w", err)
+	}
+
+	return newResult(allowed, tokens, rule), nil
+}
+
+// Sweep deletes the idle buckets every idle duration until the context is
+// done. Failed sweeps are logged and retried on the next tick.
+func (s *PostgresStore) Sweep(ctx context.Context, l logger.Interface) {
+	ticker := time.NewTicker(s.idle)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.sweep(ctx); err != nil {
+				l.Error(err)
+			}
+		}
+	}
+}
+
+func (s *PostgresStore) sweep(ctx context.Context) error {
+	_, err := s.Pool.Exec(ctx, _deleteIdleBuckets, s.idle.Seconds())
+	if err != nil {
+		return fmt.Errorf("PostgresStore - sweep - s.Pool.Exec: %w", err)
+	}
+
+	return nil
+}
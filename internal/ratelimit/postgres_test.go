@@ -0,0 +1,136 @@
+package ratelimit_test
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/almostinf/order_delivery_service/internal/app"
+	"github.com/almostinf/order_delivery_service/internal/ratelimit"
+	"github.com/almostinf/order_delivery_service/pkg/logger"
+	"github.com/almostinf/order_delivery_service/pkg/postgres"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// testPostgresStore connects to the migrated Postgres at PG_URL and skips the
+// test without one. The keys of the test start with the returned prefix and
+// are deleted when it ends.
+func testPostgresStore(t *testing.T, idle time.Duration) (*ratelimit.PostgresStore, *postgres.Postgres, string) {
+	t.Helper()
+
+	url := os.Getenv("PG_URL")
+	if url == "" {
+		t.Skip("PG_URL is not set")
+	}
+
+	if err := app.Migrate(logger.New("error"), url, "up"); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	pg, err := postgres.New(url, postgres.MaxPoolSize(8))
+	if err != nil {
+		t.Fatalf("postgres.New: %v", err)
+	}
+
+	prefix := uuid.NewString() + ":"
+	t.Cleanup(func() {
+		_, _ = pg.Pool.Exec(context.Background(), `DELETE FROM rate_limit_buckets WHERE key LIKE $1 || '%'`, prefix)
+		pg.Close()
+	})
+
+	return ratelimit.NewPostgresStore(pg, idle), pg, prefix
+}
+
+func TestPostgresStoreTake(t *testing.T) {
+	t.Parallel()
+
+	s, _, prefix := testPostgresStore(t, time.Minute)
+	ctx := context.Background()
+
+	// The bucket refills once in 1000 seconds, so only the burst is allowed
+	rule := ratelimit.Rule{RPS: 0.001, Burst: 2}
+
+	res, err := s.Take(ctx, prefix+"a", rule)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+	require.Equal(t, 1, res.Remaining)
+
+	res, err = s.Take(ctx, prefix+"a", rule)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+	require.Equal(t, 0, res.Remaining)
+
+	res, err = s.Take(ctx, prefix+"a", rule)
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+	require.Positive(t, res.RetryAfter)
+
+	// Other clients have their own buckets
+	res, err = s.Take(ctx, prefix+"b", rule)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+}
+
+func TestPostgresStoreTakeConcurrently(t *testing.T) {
+	t.Parallel()
+
+	s, _, prefix := testPostgresStore(t, time.Minute)
+	rule := ratelimit.Rule{RPS: 0.001, Burst: 5}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := 0
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			res, err := s.Take(context.Background(), prefix+"shared", rule)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			if res.Allowed {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, rule.Burst, allowed)
+}
+
+func TestPostgresStoreSweep(t *testing.T) {
+	t.Parallel()
+
+	s, pg, prefix := testPostgresStore(t, 100*time.Millisecond)
+	ctx := context.Background()
+
+	_, err := pg.Pool.Exec(ctx, `
+		INSERT INTO rate_limit_buckets (key, tokens, allowed, updated_at)
+		VALUES ($1, 1, TRUE, now() - interval '1 hour'), ($2, 1, TRUE, now() + interval '1 hour')`,
+		prefix+"idle", prefix+"fresh")
+	require.NoError(t, err)
+
+	sweepCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	go s.Sweep(sweepCtx, logger.New("error"))
+
+	exists := func(key string) bool {
+		var n int
+		err := pg.Pool.QueryRow(ctx, `SELECT count(*) FROM rate_limit_buckets WHERE key = $1`, key).Scan(&n)
+		require.NoError(t, err)
+
+		return n > 0
+	}
+
+	require.Eventually(t, func() bool { return !exists(prefix + "idle") }, 5*time.Second, 50*time.Millisecond)
+	require.True(t, exists(prefix+"fresh"))
+}
@@ -0,0 +1,54 @@
+// Package ratelimit keeps a token bucket per client key.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Rule is the size and the refill rate of a bucket.
+type Rule struct {
+	RPS   float64
+	Burst int
+}
+
+// Result describes the bucket after a request was counted.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // until the bucket is full again
+	RetryAfter time.Duration // until the next request is allowed, zero if allowed
+}
+
+// Store keeps the buckets. The in-memory store limits a single replica,
+// the Postgres one shares the buckets between replicas.
+type Store interface {
+	// Take takes a token from the bucket of the key, creating it by the
+	// rule on first use.
+	Take(ctx context.Context, key string, rule Rule) (Result, error)
+}
+
+func newResult(allowed bool, tokens float64, rule Rule) Result {
+	res := Result{
+		Allowed:   allowed,
+		Limit:     rule.Burst,
+		Remaining: int(math.Max(0, math.Floor(tokens))),
+		Reset:     refillDuration(float64(rule.Burst)-tokens, rule.RPS),
+	}
+
+	if !allowed {
+		res.RetryAfter = refillDuration(1-tokens, rule.RPS)
+	}
+
+	return res
+}
+
+func refillDuration(tokens, rps float64) time.Duration {
+	if tokens <= 0 || rps <= 0 {
+		return 0
+	}
+
+	return time.Duration(tokens / rps * float64(time.Second))
+}
@@ -0,0 +1,15 @@
+package tracing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLOperation(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "SELECT", sqlOperation("\n\tselect order_id FROM orders"))
+	require.Equal(t, "UPDATE", sqlOperation("UPDATE orders SET courier_id = $1"))
+	require.Equal(t, "query", sqlOperation("  "))
+}
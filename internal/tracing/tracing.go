@@ -0,0 +1,100 @@
+// Package tracing sets up the OpenTelemetry tracer provider of the service
+// and the helpers the layers start their spans with.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/almostinf/order_delivery_service/internal/entity"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const _instrumentationName = "github.com/almostinf/order_delivery_service"
+
+// Exporters supported by Setup.
+const (
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Config selects the exporter of the spans. Endpoint is the host:port of
+// the OTLP/HTTP collector, SampleRatio the share of the traces kept.
+type Config struct {
+	ServiceName    string
+	ServiceVersion string
+	Exporter       string
+	Endpoint       string
+	Insecure       bool
+	SampleRatio    float64
+}
+
+// Setup installs the global tracer provider and the W3C trace context
+// propagator. The returned function flushes the pending spans.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	var (
+		exporter sdktrace.SpanExporter
+		err      error
+	)
+
+	switch cfg.Exporter {
+	case ExporterStdout:
+		exporter, err = stdouttrace.New()
+	case ExporterOTLP:
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("tracing - Setup - unknown exporter %q", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("tracing - Setup - new %s exporter: %w", cfg.Exporter, err)
+	}
+
+	res := resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+		semconv.ServiceVersion(cfg.ServiceVersion),
+	)
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}
+
+// Start starts a span of the service tracer. Until Setup is called the
+// global provider is a no-op, so the spans cost nothing.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(_instrumentationName).Start(ctx, name, opts...)
+}
+
+// Error records the err on the span and returns it, so it can wrap the error
+// returns. Only unexpected errors mark the span as failed: a DomainError is an
+// answer to a bad request, not a failure of the service.
+func Error(span trace.Span, err error) error {
+	span.RecordError(err)
+
+	var domainErr *entity.DomainError
+	if !errors.As(err, &domainErr) {
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return err
+}
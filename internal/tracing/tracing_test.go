@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/almostinf/order_delivery_service/internal/entity"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestError(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name   string
+		err    error
+		status codes.Code
+	}{
+		{
+			name:   "unexpected error",
+			err:    errors.New("connection refused"),
+			status: codes.Error,
+		},
+		{
+			name:   "wrapped domain error",
+			err:    fmt.Errorf("OrderUseCase - Get - uc.repo.Get: %w", entity.NewDomainError(entity.ErrNotFound, "order not found")),
+			status: codes.Unset,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			recorder := tracetest.NewSpanRecorder()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+			_, span := provider.Tracer("test").Start(context.Background(), "span")
+			require.Equal(t, tc.err, Error(span, tc.err))
+			span.End()
+
+			spans := recorder.Ended()
+			require.Len(t, spans, 1)
+			require.Equal(t, tc.status, spans[0].Status().Code)
+			require.Len(t, spans[0].Events(), 1) // the error is recorded either way
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/almostinf/order_delivery_service/internal/entity"
+	"github.com/almostinf/order_delivery_service/internal/infrastructure/interfaces"
+	"github.com/almostinf/order_delivery_service/internal/tracing"
+)
+
+type AnalyticsUseCase struct {
+	repo interfaces.Analytics
+}
+
+func NewAnalyticsUseCase(r interfaces.Analytics) *AnalyticsUseCase {
+	return &AnalyticsUseCase{r}
+}
+
+func (uc *AnalyticsUseCase) GetCouriersAnalytics(ctx context.Context, filter entity.CourierAnalyticsFilter) ([]*entity.CourierAnalytics, error) {
+	ctx, span := tracing.Start(ctx, "AnalyticsUseCase - GetCouriersAnalytics")
+	defer span.End()
+
+	couriers, err := uc.repo.GetCouriersAnalytics(ctx, filter)
+	if err != nil {
+		return nil, tracing.Error(span, fmt.Errorf("AnalyticsUseCase - GetCouriersAnalytics - uc.repo.GetCouriersAnalytics: %w", err))
+	}
+
+	return couriers, nil
+}
+
+func (uc *AnalyticsUseCase) GetOrdersAnalytics(ctx context.Context, filter entity.OrderAnalyticsFilter) ([]*entity.OrderAnalytics, error) {
+	ctx, span := tracing.Start(ctx, "AnalyticsUseCase - GetOrdersAnalytics")
+	defer span.End()
+
+	orders, err := uc.repo.GetOrdersAnalytics(ctx, filter)
+	if err != nil {
+		return nil, tracing.Error(span, fmt.Errorf("AnalyticsUseCase - GetOrdersAnalytics - uc.repo.GetOrdersAnalytics: %w", err))
+	}
+
+	return orders, nil
+}
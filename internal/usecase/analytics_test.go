@@ -0,0 +1,165 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/almostinf/order_delivery_service/internal/entity"
+	mocks "github.com/almostinf/order_delivery_service/internal/mocks/repo"
+	"github.com/almostinf/order_delivery_service/internal/usecase"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func analytics(t *testing.T) (*usecase.AnalyticsUseCase, *mocks.MockAnalytics) {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	repo := mocks.NewMockAnalytics(mockCtrl)
+	analytics := usecase.NewAnalyticsUseCase(repo)
+
+	return analytics, repo
+}
+
+func TestGetCouriersAnalytics(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		ctx    context.Context
+		filter entity.CourierAnalyticsFilter
+	}
+
+	ctx := context.Background()
+	filter := entity.CourierAnalyticsFilter{SortBy: "rating", Limit: 10}
+
+	couriersAnalytics := []*entity.CourierAnalytics{}
+
+	repoErr := errors.New("some error")
+
+	testcases := []struct {
+		name  string
+		args  args
+		mock  func(repo *mocks.MockAnalytics)
+		res   []*entity.CourierAnalytics
+		isErr bool
+	}{
+		{
+			name: "success",
+			args: args{
+				ctx:    ctx,
+				filter: filter,
+			},
+			mock: func(repo *mocks.MockAnalytics) {
+				repo.EXPECT().GetCouriersAnalytics(inSpan("AnalyticsUseCase - GetCouriersAnalytics"), filter).Return(couriersAnalytics, nil).Times(1)
+			},
+			res:   couriersAnalytics,
+			isErr: false,
+		},
+		{
+			name: "repo error",
+			args: args{
+				ctx:    ctx,
+				filter: filter,
+			},
+			mock: func(repo *mocks.MockAnalytics) {
+				repo.EXPECT().GetCouriersAnalytics(inSpan("AnalyticsUseCase - GetCouriersAnalytics"), filter).Return(nil, repoErr).Times(1)
+			},
+			res:   nil,
+			isErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			analytics, repo := analytics(t)
+
+			tc.mock(repo)
+
+			res, err := analytics.GetCouriersAnalytics(tc.args.ctx, tc.args.filter)
+
+			require.Equal(t, res, tc.res)
+			if tc.isErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGetOrdersAnalytics(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		ctx    context.Context
+		filter entity.OrderAnalyticsFilter
+	}
+
+	ctx := context.Background()
+	filter := entity.OrderAnalyticsFilter{Bucket: "day"}
+
+	ordersAnalytics := []*entity.OrderAnalytics{}
+
+	repoErr := errors.New("some error")
+
+	testcases := []struct {
+		name  string
+		args  args
+		mock  func(repo *mocks.MockAnalytics)
+		res   []*entity.OrderAnalytics
+		isErr bool
+	}{
+		{
+			name: "success",
+			args: args{
+				ctx:    ctx,
+				filter: filter,
+			},
+			mock: func(repo *mocks.MockAnalytics) {
+				repo.EXPECT().GetOrdersAnalytics(inSpan("AnalyticsUseCase - GetOrdersAnalytics"), filter).Return(ordersAnalytics, nil).Times(1)
+			},
+			res:   ordersAnalytics,
+			isErr: false,
+		},
+		{
+			name: "repo error",
+			args: args{
+				ctx:    ctx,
+				filter: filter,
+			},
+			mock: func(repo *mocks.MockAnalytics) {
+				repo.EXPECT().GetOrdersAnalytics(inSpan("AnalyticsUseCase - GetOrdersAnalytics"), filter).Return(nil, repoErr).Times(1)
+			},
+			res:   nil,
+			isErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			analytics, repo := analytics(t)
+
+			tc.mock(repo)
+
+			res, err := analytics.GetOrdersAnalytics(tc.args.ctx, tc.args.filter)
+
+			require.Equal(t, res, tc.res)
+			if tc.isErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
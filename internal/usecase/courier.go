@@ -3,10 +3,12 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/almostinf/order_delivery_service/internal/entity"
 	"github.com/almostinf/order_delivery_service/internal/infrastructure/interfaces"
+	"github.com/almostinf/order_delivery_service/internal/tracing"
 	"github.com/google/uuid"
 )
 
@@ -19,46 +21,91 @@ func NewCourierUseCase(r interfaces.Courier) *CourierUseCase {
 }
 
 func (uc *CourierUseCase) Get(ctx context.Context, id uuid.UUID) (*entity.CourierResponse, error) {
+	ctx, span := tracing.Start(ctx, "CourierUseCase - Get")
+	defer span.End()
+
 	courier, err := uc.repo.Get(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("CourierUseCase - Get - uc.repo.Get: %w", err)
+		return nil, tracing.Error(span, fmt.Errorf("CourierUseCase - Get - uc.repo.Get: %w", err))
 	}
 
 	return courier, nil
 }
 
 func (uc *CourierUseCase) GetAll(ctx context.Context, limit, offset int) ([]*entity.CourierResponse, error) {
+	ctx, span := tracing.Start(ctx, "CourierUseCase - GetAll")
+	defer span.End()
+
 	couriers, err := uc.repo.GetAll(ctx, limit, offset)
 	if err != nil {
-		return nil, fmt.Errorf("CourierUseCase - GetAll - uc.repo.GetAll: %w", err)
+		return nil, tracing.Error(span, fmt.Errorf("CourierUseCase - GetAll - uc.repo.GetAll: %w", err))
 	}
 
 	return couriers, nil
 }
 
 func (uc *CourierUseCase) Create(ctx context.Context, courier *entity.Courier) (*entity.CourierResponse, error) {
+	ctx, span := tracing.Start(ctx, "CourierUseCase - Create")
+	defer span.End()
+
 	courierRes, err := uc.repo.Create(ctx, courier)
 	if err != nil {
-		return nil, fmt.Errorf("CourierUseCase - Create - uc.repo.Create: %w", err)
+		return nil, tracing.Error(span, fmt.Errorf("CourierUseCase - Create - uc.repo.Create: %w", err))
 	}
 
 	return courierRes, nil
 }
 
 func (uc *CourierUseCase) GetMetaInfo(ctx context.Context, courierID uuid.UUID, startDate time.Time, endDate time.Time) (*entity.CourierMetaInfo, error) {
+	ctx, span := tracing.Start(ctx, "CourierUseCase - GetMetaInfo")
+	defer span.End()
+
 	courierMetaInfo, err := uc.repo.GetMetaInfo(ctx, courierID, startDate, endDate)
 	if err != nil {
-		return nil, fmt.Errorf("CourierUseCase - GetMetaInfo - uc.repo.GetMetaInfo: %w", err)
+		return nil, tracing.Error(span, fmt.Errorf("CourierUseCase - GetMetaInfo - uc.repo.GetMetaInfo: %w", err))
 	}
 
 	return courierMetaInfo, nil
 }
 
 func (uc *CourierUseCase) GetAssignments(ctx context.Context, date time.Time, courierID uuid.UUID, isAllCouriers bool) ([]*entity.CourierAssignment, error) {
+	ctx, span := tracing.Start(ctx, "CourierUseCase - GetAssignments")
+	defer span.End()
+
 	courierAssignments, err := uc.repo.GetAssignments(ctx, date, courierID, isAllCouriers)
 	if err != nil {
-		return nil, fmt.Errorf("CourierUseCase - GetAssignments - uc.repo.GetAssignments: %w", err)
+		return nil, tracing.Error(span, fmt.Errorf("CourierUseCase - GetAssignments - uc.repo.GetAssignments: %w", err))
 	}
 
 	return courierAssignments, nil
 }
+
+// GetDeliveryPlan returns the groups of the courier on the date in delivery
+// order: the groups are sorted by the start of the earliest delivery hours,
+// the orders inside them keep the route sequence of the repository.
+func (uc *CourierUseCase) GetDeliveryPlan(ctx context.Context, courierID uuid.UUID, date time.Time) (*entity.CourierAssignment, error) {
+	ctx, span := tracing.Start(ctx, "CourierUseCase - GetDeliveryPlan")
+	defer span.End()
+
+	courierAssignments, err := uc.repo.GetAssignments(ctx, date, courierID, false)
+	if err != nil {
+		return nil, tracing.Error(span, fmt.Errorf("CourierUseCase - GetDeliveryPlan - uc.repo.GetAssignments: %w", err))
+	}
+
+	plan := &entity.CourierAssignment{
+		CourierID: courierID,
+		Orders:    make([]entity.OrdersGroup, 0),
+	}
+
+	for _, assignment := range courierAssignments {
+		if assignment.CourierID == courierID {
+			plan.Orders = append(plan.Orders, assignment.Orders...)
+		}
+	}
+
+	sort.SliceStable(plan.Orders, func(i, j int) bool {
+		return plan.Orders[i].DeliveryStart() < plan.Orders[j].DeliveryStart()
+	})
+
+	return plan, nil
+}
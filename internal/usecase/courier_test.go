@@ -54,7 +54,7 @@ func TestGetCourier(t *testing.T) {
 				id:  id,
 			},
 			mock: func(repo *mocks.MockCourier) {
-				repo.EXPECT().Get(ctx, id).Return(courierResponse, nil).Times(1)
+				repo.EXPECT().Get(inSpan("CourierUseCase - Get"), id).Return(courierResponse, nil).Times(1)
 			},
 			res:   courierResponse,
 			isErr: false,
@@ -66,7 +66,7 @@ func TestGetCourier(t *testing.T) {
 				id:  id,
 			},
 			mock: func(repo *mocks.MockCourier) {
-				repo.EXPECT().Get(ctx, id).Return(nil, repoErr).Times(1)
+				repo.EXPECT().Get(inSpan("CourierUseCase - Get"), id).Return(nil, repoErr).Times(1)
 			},
 			res:   nil,
 			isErr: true,
@@ -125,7 +125,7 @@ func TestGetAllCouriers(t *testing.T) {
 				offset: offset,
 			},
 			mock: func(repo *mocks.MockCourier) {
-				repo.EXPECT().GetAll(ctx, limit, offset).Return(couriersResponse, nil).Times(1)
+				repo.EXPECT().GetAll(inSpan("CourierUseCase - GetAll"), limit, offset).Return(couriersResponse, nil).Times(1)
 			},
 			res:   couriersResponse,
 			isErr: false,
@@ -138,7 +138,7 @@ func TestGetAllCouriers(t *testing.T) {
 				offset: offset,
 			},
 			mock: func(repo *mocks.MockCourier) {
-				repo.EXPECT().GetAll(ctx, limit, offset).Return(nil, repoErr).Times(1)
+				repo.EXPECT().GetAll(inSpan("CourierUseCase - GetAll"), limit, offset).Return(nil, repoErr).Times(1)
 			},
 			res:   nil,
 			isErr: true,
@@ -195,7 +195,7 @@ func TestCreateCourier(t *testing.T) {
 				courierReq: courierReq,
 			},
 			mock: func(repo *mocks.MockCourier) {
-				repo.EXPECT().Create(ctx, courierReq).Return(courierResponse, nil).Times(1)
+				repo.EXPECT().Create(inSpan("CourierUseCase - Create"), courierReq).Return(courierResponse, nil).Times(1)
 			},
 			res:   courierResponse,
 			isErr: false,
@@ -207,7 +207,7 @@ func TestCreateCourier(t *testing.T) {
 				courierReq: courierReq,
 			},
 			mock: func(repo *mocks.MockCourier) {
-				repo.EXPECT().Create(ctx, courierReq).Return(nil, repoErr).Times(1)
+				repo.EXPECT().Create(inSpan("CourierUseCase - Create"), courierReq).Return(nil, repoErr).Times(1)
 			},
 			res:   nil,
 			isErr: true,
@@ -270,7 +270,7 @@ func TestGetMetaInfoFromCourier(t *testing.T) {
 				endDate:   endDate,
 			},
 			mock: func(repo *mocks.MockCourier) {
-				repo.EXPECT().GetMetaInfo(ctx, courierID, startDate, endDate).Return(courierMetaResponse, nil).Times(1)
+				repo.EXPECT().GetMetaInfo(inSpan("CourierUseCase - GetMetaInfo"), courierID, startDate, endDate).Return(courierMetaResponse, nil).Times(1)
 			},
 			res:   courierMetaResponse,
 			isErr: false,
@@ -284,7 +284,7 @@ func TestGetMetaInfoFromCourier(t *testing.T) {
 				endDate:   endDate,
 			},
 			mock: func(repo *mocks.MockCourier) {
-				repo.EXPECT().GetMetaInfo(ctx, courierID, startDate, endDate).Return(nil, repoErr).Times(1)
+				repo.EXPECT().GetMetaInfo(inSpan("CourierUseCase - GetMetaInfo"), courierID, startDate, endDate).Return(nil, repoErr).Times(1)
 			},
 			res:   nil,
 			isErr: true,
@@ -348,7 +348,7 @@ func TestGetAssignments(t *testing.T) {
 				isAllCouriers: isAllCouriers,
 			},
 			mock: func(repo *mocks.MockCourier) {
-				repo.EXPECT().GetAssignments(ctx, date, courierID, isAllCouriers).Return(courierAssignments, nil).Times(1)
+				repo.EXPECT().GetAssignments(inSpan("CourierUseCase - GetAssignments"), date, courierID, isAllCouriers).Return(courierAssignments, nil).Times(1)
 			},
 			res:   courierAssignments,
 			isErr: false,
@@ -362,7 +362,7 @@ func TestGetAssignments(t *testing.T) {
 				isAllCouriers: isAllCouriers,
 			},
 			mock: func(repo *mocks.MockCourier) {
-				repo.EXPECT().GetAssignments(ctx, date, courierID, isAllCouriers).Return(nil, repoErr).Times(1)
+				repo.EXPECT().GetAssignments(inSpan("CourierUseCase - GetAssignments"), date, courierID, isAllCouriers).Return(nil, repoErr).Times(1)
 			},
 			res:   nil,
 			isErr: true,
@@ -390,3 +390,85 @@ func TestGetAssignments(t *testing.T) {
 		})
 	}
 }
+
+func TestGetDeliveryPlan(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	date := time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)
+	courierID := uuid.New()
+
+	// The route visits noon first, though the earliest window of morning starts before
+	noon := entity.OrderResponse{OrderID: uuid.New(), DeliveryHours: []string{"11:00-12:00"}, Sequence: 1}
+	morning := entity.OrderResponse{OrderID: uuid.New(), DeliveryHours: []string{"12:00-14:00", "09:00-10:00"}, Sequence: 2}
+	evening := entity.OrderResponse{OrderID: uuid.New(), DeliveryHours: []string{"18:00-20:00"}, Sequence: 1}
+
+	eveningGroup := entity.OrdersGroup{GroupOrderID: uuid.New(), Orders: []entity.OrderResponse{evening}}
+	dayGroup := entity.OrdersGroup{GroupOrderID: uuid.New(), Orders: []entity.OrderResponse{noon, morning}}
+
+	repoErr := errors.New("some error")
+
+	testcases := []struct {
+		name  string
+		mock  func(repo *mocks.MockCourier)
+		res   *entity.CourierAssignment
+		isErr bool
+	}{
+		{
+			name: "groups in delivery order, orders in route sequence",
+			mock: func(repo *mocks.MockCourier) {
+				repo.EXPECT().GetAssignments(inSpan("CourierUseCase - GetDeliveryPlan"), date, courierID, false).Return([]*entity.CourierAssignment{
+					{CourierID: courierID, Orders: []entity.OrdersGroup{eveningGroup, dayGroup}},
+				}, nil).Times(1)
+			},
+			res: &entity.CourierAssignment{
+				CourierID: courierID,
+				Orders: []entity.OrdersGroup{
+					dayGroup,
+					eveningGroup,
+				},
+			},
+			isErr: false,
+		},
+		{
+			name: "no orders",
+			mock: func(repo *mocks.MockCourier) {
+				repo.EXPECT().GetAssignments(inSpan("CourierUseCase - GetDeliveryPlan"), date, courierID, false).Return([]*entity.CourierAssignment{}, nil).Times(1)
+			},
+			res: &entity.CourierAssignment{
+				CourierID: courierID,
+				Orders:    []entity.OrdersGroup{},
+			},
+			isErr: false,
+		},
+		{
+			name: "repo error",
+			mock: func(repo *mocks.MockCourier) {
+				repo.EXPECT().GetAssignments(inSpan("CourierUseCase - GetDeliveryPlan"), date, courierID, false).Return(nil, repoErr).Times(1)
+			},
+			res:   nil,
+			isErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			courier, repo := courier(t)
+
+			tc.mock(repo)
+
+			res, err := courier.GetDeliveryPlan(ctx, courierID, date)
+
+			require.Equal(t, tc.res, res)
+			if tc.isErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
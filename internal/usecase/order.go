@@ -7,6 +7,9 @@ import (
 
 	"github.com/almostinf/order_delivery_service/internal/entity"
 	"github.com/almostinf/order_delivery_service/internal/infrastructure/interfaces"
+	"github.com/almostinf/order_delivery_service/internal/metrics"
+	"github.com/almostinf/order_delivery_service/internal/tracing"
+	"github.com/almostinf/order_delivery_service/pkg/logger"
 	"github.com/google/uuid"
 )
 
@@ -19,55 +22,138 @@ func NewOrderUseCase(r interfaces.Order) *OrderUseCase {
 }
 
 func (uc *OrderUseCase) Get(ctx context.Context, id uuid.UUID) (*entity.OrderResponse, error) {
+	ctx, span := tracing.Start(ctx, "OrderUseCase - Get")
+	defer span.End()
+
 	order, err := uc.repo.Get(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("OrderUseCase - Get - uc.repo.Get: %w", err)
+		return nil, tracing.Error(span, fmt.Errorf("OrderUseCase - Get - uc.repo.Get: %w", err))
 	}
 
 	return order, nil
 }
 
 func (uc *OrderUseCase) GetAll(ctx context.Context, limit, offset int) ([]*entity.OrderResponse, error) {
+	ctx, span := tracing.Start(ctx, "OrderUseCase - GetAll")
+	defer span.End()
+
 	orders, err := uc.repo.GetAll(ctx, limit, offset)
 	if err != nil {
-		return nil, fmt.Errorf("OrderUseCase - GetAll - uc.repo.GetAll: %w", err)
+		return nil, tracing.Error(span, fmt.Errorf("OrderUseCase - GetAll - uc.repo.GetAll: %w", err))
 	}
 
 	return orders, nil
 }
 
 func (uc *OrderUseCase) Create(ctx context.Context, order *entity.Order) (*entity.OrderResponse, error) {
+	ctx, span := tracing.Start(ctx, "OrderUseCase - Create")
+	defer span.End()
+
 	orderRes, err := uc.repo.Create(ctx, order)
 	if err != nil {
-		return nil, fmt.Errorf("OrderUseCase - Create - uc.repo.Create: %w", err)
+		return nil, tracing.Error(span, fmt.Errorf("OrderUseCase - Create - uc.repo.Create: %w", err))
 	}
 
+	metrics.OrderCreated(orderRes.Regions)
+
 	return orderRes, nil
 }
 
 func (uc *OrderUseCase) Complete(ctx context.Context, completeInfoReq []entity.CompleteInfo) ([]*entity.OrderResponse, error) {
-	orderRes, err := uc.repo.Complete(ctx, completeInfoReq)
+	ctx, span := tracing.Start(ctx, "OrderUseCase - Complete")
+	defer span.End()
+
+	completed, err := uc.repo.Complete(ctx, completeInfoReq)
 	if err != nil {
-		return nil, fmt.Errorf("OrderUseCase - Complete - uc.repo.Complete: %w", err)
+		return nil, tracing.Error(span, fmt.Errorf("OrderUseCase - Complete - uc.repo.Complete: %w", err))
+	}
+
+	orderRes := make([]*entity.OrderResponse, 0, len(completed))
+	for _, order := range completed {
+		// A repeated completion was counted the first time
+		if !order.Repeated {
+			metrics.OrderCompleted(order.Regions, order.CourierType)
+		}
+		orderRes = append(orderRes, &order.OrderResponse)
 	}
 
 	return orderRes, nil
 }
 
 func (uc *OrderUseCase) SetCourierID(ctx context.Context, orderID uuid.UUID, courierID uuid.UUID) (*entity.OrderResponse, error) {
+	ctx, span := tracing.Start(ctx, "OrderUseCase - SetCourierID")
+	defer span.End()
+
 	orderRes, err := uc.repo.SetCourierID(ctx, orderID, courierID)
 	if err != nil {
-		return nil, fmt.Errorf("OrderUseCase - SetCourierID - uc.repo.SetCourierID: %w", err)
+		return nil, tracing.Error(span, fmt.Errorf("OrderUseCase - SetCourierID - uc.repo.SetCourierID: %w", err))
 	}
 
+	logger.FromContext(ctx).With("order_id", orderID).With("courier_id", courierID).Info("OrderUseCase - SetCourierID - order assigned to courier")
+
 	return orderRes, nil
 }
 
+func (uc *OrderUseCase) Unassign(ctx context.Context, orderID uuid.UUID) (*entity.OrderResponse, error) {
+	ctx, span := tracing.Start(ctx, "OrderUseCase - Unassign")
+	defer span.End()
+
+	orderRes, err := uc.repo.Unassign(ctx, orderID)
+	if err != nil {
+		return nil, tracing.Error(span, fmt.Errorf("OrderUseCase - Unassign - uc.repo.Unassign: %w", err))
+	}
+
+	logger.FromContext(ctx).With("order_id", orderID).Info("OrderUseCase - Unassign - order unassigned")
+
+	return orderRes, nil
+}
+
+func (uc *OrderUseCase) Reassign(ctx context.Context, date time.Time, fromCourierID uuid.UUID, toCourierID uuid.UUID) (*entity.CourierAssignment, error) {
+	ctx, span := tracing.Start(ctx, "OrderUseCase - Reassign")
+	defer span.End()
+
+	courierAssignment, err := uc.repo.Reassign(ctx, date, fromCourierID, toCourierID)
+	if err != nil {
+		return nil, tracing.Error(span, fmt.Errorf("OrderUseCase - Reassign - uc.repo.Reassign: %w", err))
+	}
+
+	logger.FromContext(ctx).With("from_courier_id", fromCourierID).With("to_courier_id", toCourierID).With("date", date.Format("2006-01-02")).
+		Info("OrderUseCase - Reassign - orders reassigned")
+
+	return courierAssignment, nil
+}
+
+func (uc *OrderUseCase) StartDelivery(ctx context.Context, courierID uuid.UUID, orderIDs []uuid.UUID, startTime time.Time) ([]*entity.OrderResponse, error) {
+	ctx, span := tracing.Start(ctx, "OrderUseCase - StartDelivery")
+	defer span.End()
+
+	orders, err := uc.repo.StartDelivery(ctx, courierID, orderIDs, startTime)
+	if err != nil {
+		return nil, tracing.Error(span, fmt.Errorf("OrderUseCase - StartDelivery - uc.repo.StartDelivery: %w", err))
+	}
+
+	logger.FromContext(ctx).With("courier_id", courierID).With("orders", len(orderIDs)).Info("OrderUseCase - StartDelivery - delivery started")
+
+	return orders, nil
+}
+
 func (uc *OrderUseCase) Assign(ctx context.Context, date time.Time) ([]*entity.CourierAssignment, error) {
-	courierAssignments, err := uc.repo.Assign(ctx, date)
+	ctx, span := tracing.Start(ctx, "OrderUseCase - Assign")
+	defer span.End()
+
+	start := time.Now()
+
+	run, err := uc.repo.Assign(ctx, date)
 	if err != nil {
-		return nil, fmt.Errorf("OrderUseCase - Assign - uc.repo.Assign: %w", err)
+		return nil, tracing.Error(span, fmt.Errorf("OrderUseCase - Assign - uc.repo.Assign: %w", err))
+	}
+
+	for _, order := range run.Assigned {
+		metrics.OrderAssigned(order.Region, order.CourierType)
 	}
+	metrics.AssignmentRun(time.Since(start), run.Unassigned)
+
+	logger.FromContext(ctx).With("date", date.Format("2006-01-02")).With("couriers", len(run.Couriers)).Info("OrderUseCase - Assign - orders assigned")
 
-	return courierAssignments, nil
+	return run.Couriers, nil
 }
@@ -54,7 +54,7 @@ func TestGetOrder(t *testing.T) {
 				id:  id,
 			},
 			mock: func(repo *mocks.MockOrder) {
-				repo.EXPECT().Get(ctx, id).Return(orderResponse, nil).Times(1)
+				repo.EXPECT().Get(inSpan("OrderUseCase - Get"), id).Return(orderResponse, nil).Times(1)
 			},
 			res:   orderResponse,
 			isErr: false,
@@ -66,7 +66,7 @@ func TestGetOrder(t *testing.T) {
 				id:  id,
 			},
 			mock: func(repo *mocks.MockOrder) {
-				repo.EXPECT().Get(ctx, id).Return(nil, repoErr).Times(1)
+				repo.EXPECT().Get(inSpan("OrderUseCase - Get"), id).Return(nil, repoErr).Times(1)
 			},
 			res:   nil,
 			isErr: true,
@@ -125,7 +125,7 @@ func TestGetAllOrders(t *testing.T) {
 				offset: offset,
 			},
 			mock: func(repo *mocks.MockOrder) {
-				repo.EXPECT().GetAll(ctx, limit, offset).Return(ordersResponse, nil).Times(1)
+				repo.EXPECT().GetAll(inSpan("OrderUseCase - GetAll"), limit, offset).Return(ordersResponse, nil).Times(1)
 			},
 			res:   ordersResponse,
 			isErr: false,
@@ -138,7 +138,7 @@ func TestGetAllOrders(t *testing.T) {
 				offset: offset,
 			},
 			mock: func(repo *mocks.MockOrder) {
-				repo.EXPECT().GetAll(ctx, limit, offset).Return(nil, repoErr).Times(1)
+				repo.EXPECT().GetAll(inSpan("OrderUseCase - GetAll"), limit, offset).Return(nil, repoErr).Times(1)
 			},
 			res:   nil,
 			isErr: true,
@@ -195,7 +195,7 @@ func TestCreateOrder(t *testing.T) {
 				orderReq: orderReq,
 			},
 			mock: func(repo *mocks.MockOrder) {
-				repo.EXPECT().Create(ctx, orderReq).Return(orderResponse, nil).Times(1)
+				repo.EXPECT().Create(inSpan("OrderUseCase - Create"), orderReq).Return(orderResponse, nil).Times(1)
 			},
 			res:   orderResponse,
 			isErr: false,
@@ -207,7 +207,7 @@ func TestCreateOrder(t *testing.T) {
 				orderReq: orderReq,
 			},
 			mock: func(repo *mocks.MockOrder) {
-				repo.EXPECT().Create(ctx, orderReq).Return(nil, repoErr).Times(1)
+				repo.EXPECT().Create(inSpan("OrderUseCase - Create"), orderReq).Return(nil, repoErr).Times(1)
 			},
 			res:   nil,
 			isErr: true,
@@ -246,7 +246,11 @@ func TestCompleteOrder(t *testing.T) {
 
 	ctx := context.Background()
 	var completeInfoReq []entity.CompleteInfo
-	var orderResponse []*entity.OrderResponse
+	completed := []*entity.CompletedOrder{
+		{OrderResponse: entity.OrderResponse{OrderID: uuid.New(), Regions: 1}, CourierType: "FOOT"},
+		{OrderResponse: entity.OrderResponse{OrderID: uuid.New(), Regions: 2}, CourierType: "AUTO", Repeated: true},
+	}
+	orderResponse := []*entity.OrderResponse{&completed[0].OrderResponse, &completed[1].OrderResponse}
 
 	repoErr := errors.New("some error")
 
@@ -264,7 +268,7 @@ func TestCompleteOrder(t *testing.T) {
 				completeInfoReq: completeInfoReq,
 			},
 			mock: func(repo *mocks.MockOrder) {
-				repo.EXPECT().Complete(ctx, completeInfoReq).Return(orderResponse, nil).Times(1)
+				repo.EXPECT().Complete(inSpan("OrderUseCase - Complete"), completeInfoReq).Return(completed, nil).Times(1)
 			},
 			res:   orderResponse,
 			isErr: false,
@@ -276,7 +280,7 @@ func TestCompleteOrder(t *testing.T) {
 				completeInfoReq: completeInfoReq,
 			},
 			mock: func(repo *mocks.MockOrder) {
-				repo.EXPECT().Complete(ctx, completeInfoReq).Return(nil, repoErr).Times(1)
+				repo.EXPECT().Complete(inSpan("OrderUseCase - Complete"), completeInfoReq).Return(nil, repoErr).Times(1)
 			},
 			res:   nil,
 			isErr: true,
@@ -335,7 +339,7 @@ func TestSetCourierID(t *testing.T) {
 				courierID: courierID,
 			},
 			mock: func(repo *mocks.MockOrder) {
-				repo.EXPECT().SetCourierID(ctx, orderID, courierID).Return(orderResponse, nil).Times(1)
+				repo.EXPECT().SetCourierID(inSpan("OrderUseCase - SetCourierID"), orderID, courierID).Return(orderResponse, nil).Times(1)
 			},
 			res:   orderResponse,
 			isErr: false,
@@ -348,7 +352,7 @@ func TestSetCourierID(t *testing.T) {
 				courierID: courierID,
 			},
 			mock: func(repo *mocks.MockOrder) {
-				repo.EXPECT().SetCourierID(ctx, orderID, courierID).Return(nil, repoErr).Times(1)
+				repo.EXPECT().SetCourierID(inSpan("OrderUseCase - SetCourierID"), orderID, courierID).Return(nil, repoErr).Times(1)
 			},
 			res:   nil,
 			isErr: true,
@@ -377,6 +381,151 @@ func TestSetCourierID(t *testing.T) {
 	}
 }
 
+func TestUnassign(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		ctx     context.Context
+		orderID uuid.UUID
+	}
+
+	ctx := context.Background()
+	orderID := uuid.New()
+	orderResponse := &entity.OrderResponse{}
+
+	repoErr := errors.New("some error")
+
+	testcases := []struct {
+		name  string
+		args  args
+		mock  func(repo *mocks.MockOrder)
+		res   *entity.OrderResponse
+		isErr bool
+	}{
+		{
+			name: "success",
+			args: args{
+				ctx:     ctx,
+				orderID: orderID,
+			},
+			mock: func(repo *mocks.MockOrder) {
+				repo.EXPECT().Unassign(inSpan("OrderUseCase - Unassign"), orderID).Return(orderResponse, nil).Times(1)
+			},
+			res:   orderResponse,
+			isErr: false,
+		},
+		{
+			name: "repo error",
+			args: args{
+				ctx:     ctx,
+				orderID: orderID,
+			},
+			mock: func(repo *mocks.MockOrder) {
+				repo.EXPECT().Unassign(inSpan("OrderUseCase - Unassign"), orderID).Return(nil, repoErr).Times(1)
+			},
+			res:   nil,
+			isErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			order, repo := order(t)
+
+			tc.mock(repo)
+
+			res, err := order.Unassign(tc.args.ctx, tc.args.orderID)
+
+			require.Equal(t, res, tc.res)
+			if tc.isErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestReassign(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		ctx           context.Context
+		date          time.Time
+		fromCourierID uuid.UUID
+		toCourierID   uuid.UUID
+	}
+
+	ctx := context.Background()
+	date := time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)
+	fromCourierID, toCourierID := uuid.New(), uuid.New()
+	courierAssignment := &entity.CourierAssignment{CourierID: toCourierID}
+
+	repoErr := errors.New("some error")
+
+	testcases := []struct {
+		name  string
+		args  args
+		mock  func(repo *mocks.MockOrder)
+		res   *entity.CourierAssignment
+		isErr bool
+	}{
+		{
+			name: "success",
+			args: args{
+				ctx:           ctx,
+				date:          date,
+				fromCourierID: fromCourierID,
+				toCourierID:   toCourierID,
+			},
+			mock: func(repo *mocks.MockOrder) {
+				repo.EXPECT().Reassign(inSpan("OrderUseCase - Reassign"), date, fromCourierID, toCourierID).Return(courierAssignment, nil).Times(1)
+			},
+			res:   courierAssignment,
+			isErr: false,
+		},
+		{
+			name: "repo error",
+			args: args{
+				ctx:           ctx,
+				date:          date,
+				fromCourierID: fromCourierID,
+				toCourierID:   toCourierID,
+			},
+			mock: func(repo *mocks.MockOrder) {
+				repo.EXPECT().Reassign(inSpan("OrderUseCase - Reassign"), date, fromCourierID, toCourierID).Return(nil, repoErr).Times(1)
+			},
+			res:   nil,
+			isErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			order, repo := order(t)
+
+			tc.mock(repo)
+
+			res, err := order.Reassign(tc.args.ctx, tc.args.date, tc.args.fromCourierID, tc.args.toCourierID)
+
+			require.Equal(t, res, tc.res)
+			if tc.isErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestAssign(t *testing.T) {
 	t.Parallel()
 
@@ -387,7 +536,12 @@ func TestAssign(t *testing.T) {
 
 	ctx := context.Background()
 	date := time.Time{}
-	var courierAssignments []*entity.CourierAssignment
+	courierAssignments := []*entity.CourierAssignment{{CourierID: uuid.New()}}
+	run := &entity.AssignmentRun{
+		Couriers:   courierAssignments,
+		Assigned:   []entity.AssignedOrder{{OrderID: uuid.New(), Region: 1, CourierType: "FOOT"}},
+		Unassigned: map[int]int{2: 1},
+	}
 
 	repoErr := errors.New("some error")
 
@@ -405,7 +559,7 @@ func TestAssign(t *testing.T) {
 				date: date,
 			},
 			mock: func(repo *mocks.MockOrder) {
-				repo.EXPECT().Assign(ctx, date).Return(courierAssignments, nil).Times(1)
+				repo.EXPECT().Assign(inSpan("OrderUseCase - Assign"), date).Return(run, nil).Times(1)
 			},
 			res:   courierAssignments,
 			isErr: false,
@@ -417,7 +571,7 @@ func TestAssign(t *testing.T) {
 				date: date,
 			},
 			mock: func(repo *mocks.MockOrder) {
-				repo.EXPECT().Assign(ctx, date).Return(nil, repoErr).Times(1)
+				repo.EXPECT().Assign(inSpan("OrderUseCase - Assign"), date).Return(nil, repoErr).Times(1)
 			},
 			res:   nil,
 			isErr: true,
@@ -445,3 +599,60 @@ func TestAssign(t *testing.T) {
 		})
 	}
 }
+
+func TestStartDelivery(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	courierID := uuid.New()
+	orderIDs := []uuid.UUID{uuid.New(), uuid.New()}
+	startTime := time.Date(2023, 4, 1, 10, 0, 0, 0, time.UTC)
+	orders := []*entity.OrderResponse{{OrderID: orderIDs[0]}, {OrderID: orderIDs[1]}}
+
+	repoErr := errors.New("some error")
+
+	testcases := []struct {
+		name  string
+		mock  func(repo *mocks.MockOrder)
+		res   []*entity.OrderResponse
+		isErr bool
+	}{
+		{
+			name: "success",
+			mock: func(repo *mocks.MockOrder) {
+				repo.EXPECT().StartDelivery(inSpan("OrderUseCase - StartDelivery"), courierID, orderIDs, startTime).Return(orders, nil).Times(1)
+			},
+			res:   orders,
+			isErr: false,
+		},
+		{
+			name: "repo error",
+			mock: func(repo *mocks.MockOrder) {
+				repo.EXPECT().StartDelivery(inSpan("OrderUseCase - StartDelivery"), courierID, orderIDs, startTime).Return(nil, repoErr).Times(1)
+			},
+			res:   nil,
+			isErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			order, repo := order(t)
+
+			tc.mock(repo)
+
+			res, err := order.StartDelivery(ctx, courierID, orderIDs, startTime)
+
+			require.Equal(t, tc.res, res)
+			if tc.isErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/almostinf/order_delivery_service/internal/entity"
+	"github.com/almostinf/order_delivery_service/internal/infrastructure/interfaces"
+	"github.com/almostinf/order_delivery_service/internal/tracing"
+	"github.com/almostinf/order_delivery_service/pkg/logger"
+)
+
+// The geometry of the regions is reloaded at most once in _geometryTTL, so
+// the changes made by the other instances are picked up in time.
+const _geometryTTL = time.Minute
+
+type RegionUseCase struct {
+	repo     interfaces.Region
+	geometry *regionGeometry
+}
+
+func NewRegionUseCase(r interfaces.Region) *RegionUseCase {
+	return &RegionUseCase{r, &regionGeometry{}}
+}
+
+// regionGeometry keeps the regions with polygons in memory, so resolving an
+// address doesn't query the database.
+type regionGeometry struct {
+	mu       sync.RWMutex
+	regions  []*entity.Region
+	loadedAt time.Time
+	// generation is bumped by invalidate, so a reload that started before
+	// isn't kept
+	generation uint64
+}
+
+func (g *regionGeometry) get(ctx context.Context, repo interfaces.Region) ([]*entity.Region, error) {
+	g.mu.RLock()
+	cached, loadedAt, generation := g.regions, g.loadedAt, g.generation
+	g.mu.RUnlock()
+
+	if !loadedAt.IsZero() && time.Since(loadedAt) < _geometryTTL {
+		return cached, nil
+	}
+
+	// The database is queried without the lock, so a slow reload doesn't
+	// block the resolves served from memory or the invalidations
+	regions, err := repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The previous slice may still be read by the callers, so it isn't reused
+	withPolygons := make([]*entity.Region, 0, len(regions))
+	for _, region := range regions {
+		if region.Polygon != nil {
+			withPolygons = append(withPolygons, region)
+		}
+	}
+	sort.Slice(withPolygons, func(i, j int) bool { return withPolygons[i].RegionID < withPolygons[j].RegionID })
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.generation == generation {
+		g.regions = withPolygons
+		g.loadedAt = time.Now()
+	}
+
+	return withPolygons, nil
+}
+
+func (g *regionGeometry) invalidate() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.loadedAt = time.Time{}
+	g.generation++
+}
+
+func (uc *RegionUseCase) GetAll(ctx context.Context) ([]*entity.Region, error) {
+	ctx, span := tracing.Start(ctx, "RegionUseCase - GetAll")
+	defer span.End()
+
+	regions, err := uc.repo.GetAll(ctx)
+	if err != nil {
+		return nil, tracing.Error(span, fmt.Errorf("RegionUseCase - GetAll - uc.repo.GetAll: %w", err))
+	}
+
+	return regions, nil
+}
+
+func (uc *RegionUseCase) Get(ctx context.Context, id int) (*entity.Region, error) {
+	ctx, span := tracing.Start(ctx, "RegionUseCase - Get")
+	defer span.End()
+
+	region, err := uc.repo.Get(ctx, id)
+	if err != nil {
+		return nil, tracing.Error(span, fmt.Errorf("RegionUseCase - Get - uc.repo.Get: %w", err))
+	}
+
+	return region, nil
+}
+
+// Put creates or replaces the region, the neighbors are stored sorted and
+// without duplicates.
+func (uc *RegionUseCase) Put(ctx context.Context, region *entity.Region) (*entity.Region, error) {
+	ctx, span := tracing.Start(ctx, "RegionUseCase - Put")
+	defer span.End()
+
+	region.Neighbors = uniqueSorted(region.Neighbors)
+
+	regionRes, err := uc.repo.Put(ctx, region)
+	if err != nil {
+		return nil, tracing.Error(span, fmt.Errorf("RegionUseCase - Put - uc.repo.Put: %w", err))
+	}
+	uc.geometry.invalidate()
+
+	logger.FromContext(ctx).With("region_id", region.RegionID).With("neighbors", len(region.Neighbors)).Info("RegionUseCase - Put - region saved")
+
+	return regionRes, nil
+}
+
+func (uc *RegionUseCase) Delete(ctx context.Context, id int) error {
+	ctx, span := tracing.Start(ctx, "RegionUseCase - Delete")
+	defer span.End()
+
+	if err := uc.repo.Delete(ctx, id); err != nil {
+		return tracing.Error(span, fmt.Errorf("RegionUseCase - Delete - uc.repo.Delete: %w", err))
+	}
+	uc.geometry.invalidate()
+
+	logger.FromContext(ctx).With("region_id", id).Info("RegionUseCase - Delete - region deleted")
+
+	return nil
+}
+
+// Unknown returns the ids missing from the catalog.
+func (uc *RegionUseCase) Unknown(ctx context.Context, ids []int) ([]int, error) {
+	ctx, span := tracing.Start(ctx, "RegionUseCase - Unknown")
+	defer span.End()
+
+	unknown, err := uc.repo.Unknown(ctx, ids)
+	if err != nil {
+		return nil, tracing.Error(span, fmt.Errorf("RegionUseCase - Unknown - uc.repo.Unknown: %w", err))
+	}
+
+	return unknown, nil
+}
+
+// Resolve returns the region containing the point, the lowest id wins when
+// the polygons overlap. The points outside of every region are rejected with
+// entity.ErrValidation.
+func (uc *RegionUseCase) Resolve(ctx context.Context, latitude, longitude float64) (int, error) {
+	ctx, span := tracing.Start(ctx, "RegionUseCase - Resolve")
+	defer span.End()
+
+	regions, err := uc.geometry.get(ctx, uc.repo)
+	if err != nil {
+		return 0, tracing.Error(span, fmt.Errorf("RegionUseCase - Resolve - uc.geometry.get: %w", err))
+	}
+
+	for _, region := range regions {
+		if region.Polygon.Contains(longitude, latitude) {
+			return region.RegionID, nil
+		}
+	}
+
+	return 0, entity.NewDomainError(entity.ErrValidation, "the address is outside of the service area")
+}
+
+func uniqueSorted(ids []int) []int {
+	unique := make([]int, 0, len(ids))
+
+	seen := make(map[int]struct{}, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; !ok {
+			seen[id] = struct{}{}
+			unique = append(unique, id)
+		}
+	}
+	sort.Ints(unique)
+
+	return unique
+}
@@ -0,0 +1,198 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/almostinf/order_delivery_service/internal/entity"
+	mocks "github.com/almostinf/order_delivery_service/internal/mocks/repo"
+	"github.com/almostinf/order_delivery_service/internal/usecase"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func region(t *testing.T) (*usecase.RegionUseCase, *mocks.MockRegion) {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	repo := mocks.NewMockRegion(mockCtrl)
+	region := usecase.NewRegionUseCase(repo)
+
+	return region, repo
+}
+
+func TestPutRegion(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		ctx    context.Context
+		region *entity.Region
+	}
+
+	ctx := context.Background()
+	saved := &entity.Region{RegionID: 1, Name: "Center", Neighbors: []int{2, 3}}
+
+	repoErr := errors.New("some error")
+
+	testcases := []struct {
+		name  string
+		args  args
+		mock  func(repo *mocks.MockRegion)
+		res   *entity.Region
+		isErr bool
+	}{
+		{
+			name: "neighbors sorted without duplicates",
+			args: args{
+				ctx:    ctx,
+				region: &entity.Region{RegionID: 1, Name: "Center", Neighbors: []int{3, 2, 3}},
+			},
+			mock: func(repo *mocks.MockRegion) {
+				repo.EXPECT().Put(inSpan("RegionUseCase - Put"), saved).Return(saved, nil).Times(1)
+			},
+			res:   saved,
+			isErr: false,
+		},
+		{
+			name: "no neighbors",
+			args: args{
+				ctx:    ctx,
+				region: &entity.Region{RegionID: 1, Name: "Center"},
+			},
+			mock: func(repo *mocks.MockRegion) {
+				repo.EXPECT().Put(inSpan("RegionUseCase - Put"), &entity.Region{RegionID: 1, Name: "Center", Neighbors: []int{}}).Return(saved, nil).Times(1)
+			},
+			res:   saved,
+			isErr: false,
+		},
+		{
+			name: "repo error",
+			args: args{
+				ctx:    ctx,
+				region: &entity.Region{RegionID: 1, Name: "Center", Neighbors: []int{2, 3}},
+			},
+			mock: func(repo *mocks.MockRegion) {
+				repo.EXPECT().Put(inSpan("RegionUseCase - Put"), saved).Return(nil, repoErr).Times(1)
+			},
+			res:   nil,
+			isErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			region, repo := region(t)
+
+			tc.mock(repo)
+
+			res, err := region.Put(tc.args.ctx, tc.args.region)
+
+			require.Equal(t, res, tc.res)
+			if tc.isErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestResolveRegion(t *testing.T) {
+	t.Parallel()
+
+	square := func(x0, y0, x1, y1 float64) [][]float64 {
+		return [][]float64{{x0, y0}, {x1, y0}, {x1, y1}, {x0, y1}, {x0, y0}}
+	}
+
+	regions := []*entity.Region{
+		{RegionID: 3, Name: "Overlap", Polygon: &entity.Polygon{Type: "Polygon", Coordinates: [][][]float64{square(1, 1, 3, 3)}}},
+		{RegionID: 1, Name: "Center", Polygon: &entity.Polygon{Type: "Polygon", Coordinates: [][][]float64{square(0, 0, 2, 2), square(0.5, 0.5, 1, 1)}}},
+		{RegionID: 2, Name: "Without polygon"},
+	}
+
+	testcases := []struct {
+		name      string
+		latitude  float64
+		longitude float64
+		res       int
+		err       error
+	}{
+		{name: "inside", latitude: 0.2, longitude: 1.5, res: 1},
+		{name: "overlap resolves to the lowest id", latitude: 1.5, longitude: 1.5, res: 1},
+		{name: "inside the hole", latitude: 0.7, longitude: 0.7, err: entity.ErrValidation},
+		{name: "inside the second region", latitude: 2.5, longitude: 2.5, res: 3},
+		{name: "outside", latitude: 5, longitude: 5, err: entity.ErrValidation},
+	}
+
+	region, repo := region(t)
+
+	// The geometry is loaded once and served from memory afterwards
+	repo.EXPECT().GetAll(inSpan("RegionUseCase - Resolve")).Return(regions, nil).Times(1)
+
+	// The subtests share the cache, so they run one after the other
+	for _, tc := range testcases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := region.Resolve(context.Background(), tc.latitude, tc.longitude)
+
+			require.Equal(t, tc.res, res)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestResolveRegionReloadsWithoutLock(t *testing.T) {
+	t.Parallel()
+
+	regions := []*entity.Region{
+		{RegionID: 1, Name: "Center", Polygon: &entity.Polygon{Type: "Polygon", Coordinates: [][][]float64{{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}}}},
+	}
+	saved := &entity.Region{RegionID: 2, Name: "North", Neighbors: []int{}}
+
+	region, repo := region(t)
+
+	loading, release := make(chan struct{}), make(chan struct{})
+	gomock.InOrder(
+		repo.EXPECT().GetAll(inSpan("RegionUseCase - Resolve")).DoAndReturn(func(context.Context) ([]*entity.Region, error) {
+			close(loading)
+			<-release
+
+			return regions, nil
+		}).Times(1),
+		// The region saved during the first load invalidates what it read
+		repo.EXPECT().GetAll(inSpan("RegionUseCase - Resolve")).Return(regions, nil).Times(1),
+	)
+	repo.EXPECT().Put(inSpan("RegionUseCase - Put"), saved).Return(saved, nil).Times(1)
+
+	resolved := make(chan error, 1)
+	go func() {
+		_, err := region.Resolve(context.Background(), 1, 1)
+		resolved <- err
+	}()
+	<-loading
+
+	// The save doesn't wait for the load to end
+	_, err := region.Put(context.Background(), saved)
+	require.NoError(t, err)
+
+	close(release)
+	require.NoError(t, <-resolved)
+
+	for i := 0; i < 2; i++ {
+		res, err := region.Resolve(context.Background(), 1, 1)
+		require.NoError(t, err)
+		require.Equal(t, 1, res)
+	}
+}
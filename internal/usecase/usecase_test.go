@@ -0,0 +1,43 @@
+package usecase_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestMain records the spans, so the repositories can be expected to get the
+// span of the use case in their context.
+func TestMain(m *testing.M) {
+	otel.SetTracerProvider(sdktrace.NewTracerProvider())
+
+	os.Exit(m.Run())
+}
+
+// inSpan matches a context that carries the span with the given name.
+func inSpan(name string) spanMatcher {
+	return spanMatcher{name: name}
+}
+
+type spanMatcher struct {
+	name string
+}
+
+func (m spanMatcher) Matches(x interface{}) bool {
+	ctx, ok := x.(context.Context)
+	if !ok {
+		return false
+	}
+
+	span, ok := trace.SpanFromContext(ctx).(sdktrace.ReadOnlySpan)
+
+	return ok && span.Name() == m.name
+}
+
+func (m spanMatcher) String() string {
+	return "is a context with the span " + m.name
+}
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS on_time BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE orders o SET on_time = EXISTS (
+    SELECT 1 FROM unnest(o.delivery_hours) AS dh
+    WHERE o.completed_time::time BETWEEN split_part(dh, '-', 1)::time AND split_part(dh, '-', 2)::time
+)
+WHERE o.completed_time <> '0001-01-01 00:00:00';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE orders DROP COLUMN IF EXISTS on_time;
+-- +goose StatementEnd
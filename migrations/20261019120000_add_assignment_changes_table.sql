@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS assignment_changes (
+    id BIGSERIAL NOT NULL PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(order_id),
+    from_courier_id UUID NULL REFERENCES couriers(courier_id),
+    to_courier_id UUID NULL REFERENCES couriers(courier_id),
+    distribution_date TIMESTAMP NOT NULL,
+    reason TEXT NOT NULL,
+    changed_at TIMESTAMP NOT NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS assignment_changes;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS regions (
+    region_id INT NOT NULL PRIMARY KEY CHECK (region_id >= 0),
+    name TEXT NOT NULL,
+    polygon JSONB NULL,
+    neighbors INT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+-- The regions already used by couriers and orders stay valid
+INSERT INTO regions (region_id, name)
+SELECT region_id, 'Region ' || region_id
+FROM (
+    SELECT unnest(regions) AS region_id FROM couriers
+    UNION
+    SELECT regions FROM orders
+) used
+WHERE region_id >= 0
+ON CONFLICT (region_id) DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS regions;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS address TEXT NULL;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION NULL;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE orders DROP COLUMN IF EXISTS longitude;
+ALTER TABLE orders DROP COLUMN IF EXISTS latitude;
+ALTER TABLE orders DROP COLUMN IF EXISTS address;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivery_status TEXT NOT NULL DEFAULT ''
+    CHECK (delivery_status IN ('', 'ON_TIME', 'EARLY', 'LATE'));
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE orders o SET delivery_status = CASE
+    WHEN o.on_time THEN 'ON_TIME'
+    WHEN o.completed_time::time < ALL (SELECT split_part(dh, '-', 1)::time FROM unnest(o.delivery_hours) AS dh) THEN 'EARLY'
+    ELSE 'LATE'
+END
+WHERE o.completed_time <> '0001-01-01 00:00:00';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE orders DROP COLUMN IF EXISTS delivery_status;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS group_order_id UUID NULL;
+-- +goose StatementEnd
+
+-- The distributed orders get the groups the assignments showed so far: the
+-- orders of a courier in a region on a date, heaviest first, split by the
+-- max number of orders in a group of the courier type
+-- +goose StatementBegin
+UPDATE orders o SET group_order_id = g.group_order_id
+FROM (
+    SELECT o.order_id, md5(
+        o.courier_id::text || '/' || o.distribution_date::date::text || '/' || o.regions::text || '/' ||
+        ((row_number() OVER (PARTITION BY o.courier_id, o.distribution_date, o.regions ORDER BY o.weight DESC, o.order_id) - 1)
+            / CASE c.courier_type WHEN 'FOOT' THEN 2 WHEN 'BIKE' THEN 4 ELSE 7 END)::text
+    )::uuid AS group_order_id
+    FROM orders o
+    JOIN couriers c ON c.courier_id = o.courier_id
+    WHERE o.distribution_date <> '0001-01-01 00:00:00'
+) g
+WHERE o.order_id = g.order_id;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS orders_courier_id_distribution_date_idx ON orders (courier_id, distribution_date);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS orders_courier_id_distribution_date_idx;
+ALTER TABLE orders DROP COLUMN IF EXISTS group_order_id;
+-- +goose StatementEnd
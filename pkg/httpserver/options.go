@@ -0,0 +1,44 @@
+package httpserver
+
+import (
+	"net"
+	"time"
+)
+
+// Option -.
+type Option func(*Server)
+
+// Port -.
+func Port(port string) Option {
+	return func(s *Server) {
+		s.server.Addr = net.JoinHostPort("", port)
+	}
+}
+
+// ReadTimeout -.
+func ReadTimeout(timeout time.Duration) Option {
+	return func(s *Server) {
+		s.server.ReadTimeout = timeout
+	}
+}
+
+// WriteTimeout -.
+func WriteTimeout(timeout time.Duration) Option {
+	return func(s *Server) {
+		s.server.WriteTimeout = timeout
+	}
+}
+
+// IdleTimeout -.
+func IdleTimeout(timeout time.Duration) Option {
+	return func(s *Server) {
+		s.server.IdleTimeout = timeout
+	}
+}
+
+// ShutdownTimeout -.
+func ShutdownTimeout(timeout time.Duration) Option {
+	return func(s *Server) {
+		s.shutdownTimeout = timeout
+	}
+}
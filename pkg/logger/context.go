@@ -0,0 +1,26 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+)
+
+type contextKey struct{}
+
+var _nop Interface = &Logger{logger: func() *zerolog.Logger { l := zerolog.Nop(); return &l }()}
+
+// WithContext stores the request-scoped logger in the context.
+func WithContext(ctx context.Context, l Interface) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger stored by WithContext. Messages are
+// dropped when the context has no logger, e.g. in unit tests.
+func FromContext(ctx context.Context) Interface {
+	if l, ok := ctx.Value(contextKey{}).(Interface); ok {
+		return l
+	}
+
+	return _nop
+}
@@ -0,0 +1,54 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Option -.
+type Option func(*Postgres)
+
+// MaxPoolSize -.
+func MaxPoolSize(size int) Option {
+	return func(c *Postgres) {
+		c.maxPoolSize = size
+	}
+}
+
+// MinPoolSize -.
+func MinPoolSize(size int) Option {
+	return func(c *Postgres) {
+		c.minPoolSize = size
+	}
+}
+
+// ConnAttempts -.
+func ConnAttempts(attempts int) Option {
+	return func(c *Postgres) {
+		c.connAttempts = attempts
+	}
+}
+
+// ConnTimeout is the pause between the connection attempts.
+func ConnTimeout(timeout time.Duration) Option {
+	return func(c *Postgres) {
+		c.connTimeout = timeout
+	}
+}
+
+// StatementTimeout aborts the statements running longer than the timeout,
+// zero disables it.
+func StatementTimeout(timeout time.Duration) Option {
+	return func(c *Postgres) {
+		c.statementTimeout = timeout
+	}
+}
+
+// Tracer is called around every query of the pool, a pgx.BatchTracer is
+// called around the batches too.
+func Tracer(tracer pgx.QueryTracer) Option {
+	return func(c *Postgres) {
+		c.tracer = tracer
+	}
+}
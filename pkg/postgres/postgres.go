@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strconv"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -17,27 +19,44 @@ const (
 
 // Postgres -.
 type Postgres struct {
-	maxPoolSize  int
-	connAttempts int
-	connTimeout  time.Duration
+	maxPoolSize      int
+	minPoolSize      int
+	connAttempts     int
+	connTimeout      time.Duration
+	statementTimeout time.Duration
+	tracer           pgx.QueryTracer
 
 	Pool *pgxpool.Pool
 }
 
 // New -.
-func New(url string) (*Postgres, error) {
+func New(url string, opts ...Option) (*Postgres, error) {
 	pg := &Postgres{
 		maxPoolSize:  _defaultMaxPoolSize,
 		connAttempts: _defaultConnAttempts,
 		connTimeout:  _defaultConnTimeout,
 	}
 
+	// Custom options
+	for _, opt := range opts {
+		opt(pg)
+	}
+
 	poolConfig, err := pgxpool.ParseConfig(url)
 	if err != nil {
 		return nil, fmt.Errorf("postgres - NewPostgres - pgxpool.ParseConfig: %w", err)
 	}
 
 	poolConfig.MaxConns = int32(pg.maxPoolSize)
+	poolConfig.MinConns = int32(pg.minPoolSize)
+
+	if pg.statementTimeout > 0 {
+		poolConfig.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(pg.statementTimeout.Milliseconds(), 10)
+	}
+
+	if pg.tracer != nil {
+		poolConfig.ConnConfig.Tracer = pg.tracer
+	}
 
 	for pg.connAttempts > 0 {
 		pg.Pool, err = pgxpool.NewWithConfig(context.Background(), poolConfig)